@@ -0,0 +1,67 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/kubebadges/kubebadges/internal/config"
+)
+
+// User is an authenticated caller of the admin API.
+type User struct {
+	Name   string
+	UID    string
+	Groups []string
+}
+
+// Authenticator resolves a bearer token to a user. A nil user with a nil
+// error means the token is not one the authenticator knows about.
+type Authenticator interface {
+	Authenticate(ctx context.Context, token string) (*User, error)
+}
+
+// Chain tries each authenticator in turn and returns the first user found.
+type Chain []Authenticator
+
+func (chain Chain) Authenticate(ctx context.Context, token string) (*User, error) {
+	var errs []error
+	for _, authenticator := range chain {
+		user, err := authenticator.Authenticate(ctx, token)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if user != nil {
+			return user, nil
+		}
+	}
+	return nil, errors.Join(errs...)
+}
+
+// NewAuthenticator builds the authenticators enabled in the configuration,
+// in the order static tokens, OIDC, TokenReview. It returns nil when none is
+// enabled, which leaves the admin API open.
+func NewAuthenticator(cfg *config.Config, reviewer TokenReviewer) (Authenticator, error) {
+	var chain Chain
+	if len(cfg.AuthTokenFile) > 0 {
+		tokens, err := LoadTokenFile(cfg.AuthTokenFile)
+		if err != nil {
+			return nil, err
+		}
+		chain = append(chain, tokens)
+	}
+	if len(cfg.AuthOIDCIssuerURL) > 0 {
+		chain = append(chain, NewOIDC(cfg.AuthOIDCIssuerURL, cfg.AuthOIDCClientID, cfg.AuthOIDCUsernameClaim, cfg.AuthOIDCGroupsClaim,
+			cfg.AuthOIDCUsernamePrefix, cfg.AuthOIDCGroupsPrefix))
+	}
+	if cfg.AuthTokenReview {
+		chain = append(chain, NewTokenReview(reviewer))
+	}
+
+	if len(chain) == 0 {
+		slog.Warn("no authentication configured, the admin API is open to anyone who can reach it")
+		return nil, nil
+	}
+	return chain, nil
+}
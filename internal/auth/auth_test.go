@@ -0,0 +1,171 @@
+package auth
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+)
+
+func TestLoadTokenFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    StaticTokens
+		wantErr bool
+	}{
+		{
+			name:    "with groups",
+			content: "secret,alice,1,\"admins,ops\"\n",
+			want:    StaticTokens{"secret": {Name: "alice", UID: "1", Groups: []string{"admins", "ops"}}},
+		},
+		{
+			name:    "without groups and comments",
+			content: "# ci\nci-token,ci,2\n",
+			want:    StaticTokens{"ci-token": {Name: "ci", UID: "2"}},
+		},
+		{
+			name:    "missing uid",
+			content: "secret,alice\n",
+			wantErr: true,
+		},
+		{
+			name:    "empty token",
+			content: ",alice,1\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "tokens.csv")
+			if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			got, err := LoadTokenFile(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadTokenFile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("LoadTokenFile() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+type fakeReviewer struct {
+	calls int
+	err   error
+}
+
+func (f *fakeReviewer) ReviewToken(_ context.Context, token string) (authenticationv1.TokenReviewStatus, error) {
+	f.calls++
+	if f.err != nil {
+		return authenticationv1.TokenReviewStatus{}, f.err
+	}
+	if token != "sa-token" {
+		return authenticationv1.TokenReviewStatus{}, nil
+	}
+	return authenticationv1.TokenReviewStatus{
+		Authenticated: true,
+		User:          authenticationv1.UserInfo{Username: "system:serviceaccount:ci:deployer", UID: "3"},
+	}, nil
+}
+
+func TestChain(t *testing.T) {
+	static := StaticTokens{"secret": {Name: "alice"}}
+
+	tests := []struct {
+		name     string
+		reviewer *fakeReviewer
+		token    string
+		want     string
+		wantErr  bool
+	}{
+		{"static token", &fakeReviewer{}, "secret", "alice", false},
+		{"service account token", &fakeReviewer{}, "sa-token", "system:serviceaccount:ci:deployer", false},
+		{"unknown token", &fakeReviewer{}, "other", "", false},
+		{"review failure", &fakeReviewer{err: errors.New("unavailable")}, "sa-token", "", true},
+		{"review failure after match", &fakeReviewer{err: errors.New("unavailable")}, "secret", "alice", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chain := Chain{static, NewTokenReview(tt.reviewer)}
+			user, err := chain.Authenticate(context.Background(), tt.token)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Authenticate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			got := ""
+			if user != nil {
+				got = user.Name
+			}
+			if got != tt.want {
+				t.Errorf("Authenticate() user = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTokenReviewCache(t *testing.T) {
+	reviewer := &fakeReviewer{}
+	tokenReview := NewTokenReview(reviewer)
+	for _, token := range []string{"sa-token", "sa-token", "other", "other"} {
+		if _, err := tokenReview.Authenticate(context.Background(), token); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if reviewer.calls != 2 {
+		t.Errorf("ReviewToken() called %d times, want 2", reviewer.calls)
+	}
+}
+
+func TestTokenIssuer(t *testing.T) {
+	payload := base64.RawURLEncoding.EncodeToString([]byte(`{"iss":"https://dex.example.com","sub":"alice"}`))
+
+	tests := []struct {
+		name  string
+		token string
+		want  string
+	}{
+		{"jwt", "header." + payload + ".signature", "https://dex.example.com"},
+		{"opaque token", "secret", ""},
+		{"invalid payload", "header.!!!.signature", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tokenIssuer(tt.token); got != tt.want {
+				t.Errorf("tokenIssuer() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOIDCPrefixes(t *testing.T) {
+	claims := map[string]interface{}{"sub": "system:admin", "groups": []interface{}{"system:masters", "dev"}}
+	tests := []struct {
+		name           string
+		usernamePrefix string
+		groupsPrefix   string
+		want           User
+	}{
+		{"issuer by default", "", "", User{Name: "https://idp.example.com#system:admin", UID: "1234", Groups: []string{"https://idp.example.com#system:masters", "https://idp.example.com#dev"}}},
+		{"custom", "oidc:", "oidc:", User{Name: "oidc:system:admin", UID: "1234", Groups: []string{"oidc:system:masters", "oidc:dev"}}},
+		{"disabled", "-", "-", User{Name: "system:admin", UID: "1234", Groups: []string{"system:masters", "dev"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			oidc := NewOIDC("https://idp.example.com", "kubebadges", "sub", "groups", tt.usernamePrefix, tt.groupsPrefix)
+			user, err := oidc.userFromClaims(claims, "1234")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(*user, tt.want) {
+				t.Errorf("userFromClaims() = %+v, want %+v", *user, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/kubebadges/kubebadges/internal/config"
+	authorizationv1 "k8s.io/api/authorization/v1"
+)
+
+// Verbs checked on the kubebadges resource of the kubebadges.tcode.ltd
+// group for the admin API calls on KubeBadges and the settings.
+const (
+	VerbGet       = "get"       // read a badge of the badges API
+	VerbList      = "list"      // list the badges of a namespace, or export them
+	VerbUpdate    = "update"    // change the display name, alias or notify flag of a badge
+	VerbPublish   = "publish"   // make a badge public
+	VerbSign      = "sign"      // mint signed badge URLs
+	VerbDelete    = "delete"    // delete a badge
+	VerbConfigure = "configure" // change the kubebadge-config ConfigMap
+)
+
+const (
+	resourceGroup = "kubebadges.tcode.ltd"
+	resource      = "kubebadges"
+)
+
+// Authorizer decides whether a user may perform a verb on the KubeBadges of
+// a namespace.
+type Authorizer interface {
+	Authorize(ctx context.Context, user *User, namespace, verb string) (bool, error)
+}
+
+// AccessReviewer is implemented by k8s.KubeHelper.
+type AccessReviewer interface {
+	ReviewAccess(ctx context.Context, user, uid string, groups []string, attributes authorizationv1.ResourceAttributes) (bool, error)
+}
+
+// SubjectAccessReview authorizes with a SubjectAccessReview, so that RBAC
+// rules on kubebadges govern the admin API. Rules in the kubebadges
+// namespace govern the settings and the badges kept there, and rules in a
+// team namespace govern the KubeBadges of that namespace.
+type SubjectAccessReview struct {
+	reviewer AccessReviewer
+}
+
+func NewSubjectAccessReview(reviewer AccessReviewer) *SubjectAccessReview {
+	return &SubjectAccessReview{reviewer: reviewer}
+}
+
+func (s *SubjectAccessReview) Authorize(ctx context.Context, user *User, namespace, verb string) (bool, error) {
+	if len(namespace) == 0 {
+		namespace = config.KubeBadgeNamespace
+	}
+	return s.reviewer.ReviewAccess(ctx, user.Name, user.UID, user.Groups, authorizationv1.ResourceAttributes{
+		Namespace: namespace,
+		Verb:      verb,
+		Group:     resourceGroup,
+		Resource:  resource,
+	})
+}
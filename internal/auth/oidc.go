@@ -0,0 +1,127 @@
+package auth
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+)
+
+// OIDC authenticates ID tokens issued by an OpenID Connect provider. Tokens
+// of other issuers are passed on to the next authenticator.
+type OIDC struct {
+	issuerURL      string
+	clientID       string
+	usernameClaim  string
+	groupsClaim    string
+	usernamePrefix string
+	groupsPrefix   string
+
+	mu       sync.Mutex
+	verifier *oidc.IDTokenVerifier
+}
+
+// NewOIDC returns an OIDC authenticator. The prefixes are prepended to the
+// user name and groups, so that a provider cannot issue names such as
+// system:masters that carry RBAC of the cluster. As with the
+// --oidc-username-prefix flag of kube-apiserver, they default to
+// "<issuer>#", and "-" turns a prefix off.
+func NewOIDC(issuerURL, clientID, usernameClaim, groupsClaim, usernamePrefix, groupsPrefix string) *OIDC {
+	return &OIDC{
+		issuerURL:      issuerURL,
+		clientID:       clientID,
+		usernameClaim:  usernameClaim,
+		groupsClaim:    groupsClaim,
+		usernamePrefix: oidcPrefix(usernamePrefix, issuerURL),
+		groupsPrefix:   oidcPrefix(groupsPrefix, issuerURL),
+	}
+}
+
+func oidcPrefix(prefix, issuerURL string) string {
+	switch prefix {
+	case "":
+		return issuerURL + "#"
+	case "-":
+		return ""
+	}
+	return prefix
+}
+
+func (o *OIDC) Authenticate(ctx context.Context, token string) (*User, error) {
+	if tokenIssuer(token) != o.issuerURL {
+		return nil, nil
+	}
+	verifier, err := o.getVerifier(ctx)
+	if err != nil {
+		return nil, err
+	}
+	idToken, err := verifier.Verify(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	var claims map[string]interface{}
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, err
+	}
+	return o.userFromClaims(claims, idToken.Subject)
+}
+
+func (o *OIDC) userFromClaims(claims map[string]interface{}, subject string) (*User, error) {
+	name, ok := claims[o.usernameClaim].(string)
+	if !ok || len(name) == 0 {
+		return nil, fmt.Errorf("oidc: token has no %q claim", o.usernameClaim)
+	}
+	user := &User{Name: o.usernamePrefix + name, UID: subject}
+	switch groups := claims[o.groupsClaim].(type) {
+	case string:
+		user.Groups = []string{o.groupsPrefix + groups}
+	case []interface{}:
+		for _, group := range groups {
+			if value, ok := group.(string); ok {
+				user.Groups = append(user.Groups, o.groupsPrefix+value)
+			}
+		}
+	}
+	return user, nil
+}
+
+// getVerifier discovers the provider on first use, and again after a failed
+// discovery, so that an unreachable provider does not stop the server.
+func (o *OIDC) getVerifier(ctx context.Context) (*oidc.IDTokenVerifier, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.verifier != nil {
+		return o.verifier, nil
+	}
+	provider, err := oidc.NewProvider(ctx, o.issuerURL)
+	if err != nil {
+		return nil, err
+	}
+	o.verifier = provider.Verifier(&oidc.Config{ClientID: o.clientID})
+	return o.verifier, nil
+}
+
+// tokenIssuer reads the unverified iss claim of a JWT, or returns "" for
+// anything else.
+func tokenIssuer(token string) string {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return ""
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return ""
+	}
+	var claims struct {
+		Issuer string `json:"iss"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return ""
+	}
+	return claims.Issuer
+}
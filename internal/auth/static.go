@@ -0,0 +1,59 @@
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// StaticTokens authenticates the tokens of a token file.
+type StaticTokens map[string]*User
+
+// LoadTokenFile reads a token file in the format of the kube-apiserver
+// --token-auth-file flag: one token,user,uid,"group1,group2" line per token,
+// where the groups are optional.
+func LoadTokenFile(path string) (StaticTokens, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	reader.Comment = '#'
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	tokens := StaticTokens{}
+	for i, record := range records {
+		if len(record) < 3 || len(record[0]) == 0 || len(record[1]) == 0 {
+			return nil, fmt.Errorf("%s:%d: expected token,user,uid[,groups]", path, i+1)
+		}
+		user := &User{Name: record[1], UID: record[2]}
+		if len(record) > 3 {
+			for _, group := range strings.Split(record[3], ",") {
+				if group = strings.TrimSpace(group); len(group) > 0 {
+					user.Groups = append(user.Groups, group)
+				}
+			}
+		}
+		tokens[record[0]] = user
+	}
+	return tokens, nil
+}
+
+func (tokens StaticTokens) Authenticate(_ context.Context, token string) (*User, error) {
+	for known, user := range tokens {
+		if subtle.ConstantTimeCompare([]byte(known), []byte(token)) == 1 {
+			return user, nil
+		}
+	}
+	return nil, nil
+}
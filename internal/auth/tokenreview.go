@@ -0,0 +1,52 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/kubebadges/kubebadges/internal/cache"
+	authenticationv1 "k8s.io/api/authentication/v1"
+)
+
+// tokenReviewCacheTime bounds how long a revoked token keeps working.
+const tokenReviewCacheTime = time.Minute
+
+// TokenReviewer is implemented by k8s.KubeHelper.
+type TokenReviewer interface {
+	ReviewToken(ctx context.Context, token string) (authenticationv1.TokenReviewStatus, error)
+}
+
+// TokenReview authenticates Kubernetes tokens, such as ServiceAccount
+// tokens, with the TokenReview API. Results are cached for a minute.
+type TokenReview struct {
+	reviewer TokenReviewer
+	cache    *cache.Cache[string, *User] // key is the token hash
+}
+
+func NewTokenReview(reviewer TokenReviewer) *TokenReview {
+	return &TokenReview{
+		reviewer: reviewer,
+		cache:    cache.NewCache[string, *User](),
+	}
+}
+
+func (t *TokenReview) Authenticate(ctx context.Context, token string) (*User, error) {
+	sum := sha256.Sum256([]byte(token))
+	key := hex.EncodeToString(sum[:])
+	if user, ok := t.cache.Get(key); ok {
+		return user, nil
+	}
+
+	status, err := t.reviewer.ReviewToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	var user *User
+	if status.Authenticated {
+		user = &User{Name: status.User.Username, UID: status.User.UID, Groups: status.User.Groups}
+	}
+	t.cache.Set(key, user, tokenReviewCacheTime)
+	return user, nil
+}
@@ -7,9 +7,12 @@ import (
 	"net/http/httputil"
 	"net/url"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/kubebadges/kubebadges/internal/cache"
 	"github.com/kubebadges/kubebadges/internal/config"
+	"github.com/kubebadges/kubebadges/internal/metrics"
 )
 
 const (
@@ -18,18 +21,26 @@ const (
 	AccessControl = "Access-Control-Allow-Origin"
 )
 
+var shieldsTransport = metrics.InstrumentRoundTripper("shields", http.DefaultTransport)
+
 type BadgesHelper struct {
+	backend      string
 	targetHOST   string
 	targetScheme string
 	cacheTime    int
+	renderCache  *cache.Cache[string, []byte] // key is the format, style, texts, colors and logo
 }
 
 func NewBadgesHelper(config *config.Config) *BadgesHelper {
-	return &BadgesHelper{
+	helper := &BadgesHelper{
+		backend:      config.BadgeBackend,
 		targetHOST:   config.ShieldsHost,
 		targetScheme: config.ShieldsScheme,
 		cacheTime:    config.BadgeCacheTime,
+		renderCache:  cache.NewCache[string, []byte](),
 	}
+	metrics.RegisterCache("render", helper.renderCache.Stats)
+	return helper
 }
 
 func formatString(s string) string {
@@ -38,6 +49,107 @@ func formatString(s string) string {
 	return strings.ReplaceAll(s, " ", "_")
 }
 
+// WriteBadge writes the badge in the given format, see NegotiateFormat. Only
+// SVG goes through the configured backend, the other formats are always
+// rendered natively.
+func (b *BadgesHelper) WriteBadge(badge *BadgeBuilder, format string, c *gin.Context) {
+	b.writeBadge(badge, format, http.StatusOK, c)
+}
+
+// WriteError writes an error badge, such as "404: badge not found", in the
+// given format. The endpoint format answers with 200 and isError set, which
+// shields.io needs to show the error.
+func (b *BadgesHelper) WriteError(badge *BadgeBuilder, format string, status int, c *gin.Context) {
+	if format == FormatEndpoint {
+		c.JSON(http.StatusOK, b.endpointBadge(badge, true))
+		return
+	}
+	b.writeBadge(badge, format, status, c)
+}
+
+func (b *BadgesHelper) writeBadge(badge *BadgeBuilder, format string, status int, c *gin.Context) {
+	c.Header("Vary", "Accept")
+	switch format {
+	case FormatPNG:
+		data, err := b.render(badge, FormatPNG, badge.RenderPNG)
+		if err != nil {
+			slog.Error("failed to render png badge", "error", err)
+			c.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+		b.writeData(c, status, "image/png", data)
+	case FormatText:
+		b.writeData(c, status, "text/plain; charset=utf-8", []byte(badge.Label+": "+badge.Message+"\n"))
+	case FormatJSON:
+		c.JSON(status, gin.H{
+			"label":   badge.Label,
+			"message": badge.Message,
+			"color":   badge.MessageColor,
+		})
+	case FormatEndpoint:
+		c.JSON(status, b.endpointBadge(badge, false))
+	default:
+		if status == http.StatusOK {
+			b.CreateBadge(badge, c)
+			return
+		}
+		data, _ := b.render(badge, FormatSVG, func() ([]byte, error) { return badge.RenderSVG(), nil })
+		b.writeData(c, status, "image/svg+xml", data)
+	}
+}
+
+func (b *BadgesHelper) endpointBadge(badge *BadgeBuilder, isError bool) EndpointBadge {
+	endpoint := EndpointBadge{
+		SchemaVersion: 1,
+		Label:         badge.Label,
+		Message:       badge.Message,
+		Color:         badge.MessageColor,
+		LabelColor:    badge.LabelColor,
+		NamedLogo:     "kubernetes",
+		LogoSVG:       badge.LogoSVG,
+		Style:         badge.Style,
+		CacheSeconds:  b.cacheTime,
+		IsError:       isError,
+	}
+	if len(badge.Logo) > 0 {
+		endpoint.NamedLogo = badge.Logo
+	}
+	return endpoint
+}
+
+// CreateBadge writes the badge as SVG using the configured backend.
+func (b *BadgesHelper) CreateBadge(badge *BadgeBuilder, c *gin.Context) {
+	if b.backend == config.BadgeBackendShields {
+		b.CreateBadgeProxy(badge, c)
+		return
+	}
+
+	svg, _ := b.render(badge, FormatSVG, func() ([]byte, error) { return badge.RenderSVG(), nil })
+	b.writeData(c, http.StatusOK, "image/svg+xml", svg)
+}
+
+// render returns the cached image of the badge in the given format, or
+// renders and caches it.
+func (b *BadgesHelper) render(badge *BadgeBuilder, format string, renderFunc func() ([]byte, error)) ([]byte, error) {
+	key := strings.Join([]string{format, badge.Style, badge.Label, badge.Message, badge.MessageColor, badge.LabelColor, badge.LogoSVG}, "\x00")
+	if data, ok := b.renderCache.Get(key); ok {
+		return data, nil
+	}
+	data, err := renderFunc()
+	if err != nil {
+		return nil, err
+	}
+	b.renderCache.Set(key, data, time.Duration(b.cacheTime)*time.Second)
+	return data, nil
+}
+
+func (b *BadgesHelper) writeData(c *gin.Context, status int, contentType string, data []byte) {
+	c.Writer.Header().Set(AppNameHeader, AppName)
+	c.Writer.Header().Del(AccessControl)
+	c.Header("Cache-Control", "private, max-age=0, no-cache")
+	c.Data(status, contentType, data)
+}
+
 func (b *BadgesHelper) CreateBadgeProxy(badge *BadgeBuilder, c *gin.Context) {
 	label := formatString(badge.Label)
 	message := formatString(badge.Message)
@@ -52,6 +164,14 @@ func (b *BadgesHelper) CreateBadgeProxy(badge *BadgeBuilder, c *gin.Context) {
 	if len(badge.Style) > 0 {
 		q.Set("style", badge.Style)
 	}
+	if len(badge.LabelColor) > 0 {
+		q.Set("labelColor", badge.LabelColor)
+	}
+	if len(badge.LogoSVG) > 0 {
+		q.Set("logo", logoHref(badge.LogoSVG))
+	} else if len(badge.Logo) > 0 {
+		q.Set("logo", badge.Logo)
+	}
 
 	badgeURL.RawQuery = q.Encode()
 
@@ -61,6 +181,7 @@ func (b *BadgesHelper) CreateBadgeProxy(badge *BadgeBuilder, c *gin.Context) {
 		Director: func(req *http.Request) {
 			req.URL = badgeURL
 		},
+		Transport: shieldsTransport,
 		ModifyResponse: func(resp *http.Response) error {
 			resp.Header.Set("Cache-Control", "private, max-age=0, no-cache")
 			return nil
@@ -5,13 +5,20 @@ const (
 	Red    string = "red"
 	Green  string = "green"
 	Yellow string = "yellow"
+
+	BrightGreen string = "brightgreen"
+	Orange      string = "orange"
+	LightGrey   string = "lightgrey"
 )
 
 type BadgeBuilder struct {
 	Label        string
 	Message      string
 	MessageColor string
+	LabelColor   string
 	Style        string
+	Logo         string // simple-icons name
+	LogoSVG      string // SVG document, wins over Logo
 }
 
 func NewBadgeBuilder() *BadgeBuilder {
@@ -35,6 +42,21 @@ func (b *BadgeBuilder) SetMessageColor(messageColor string) *BadgeBuilder {
 	return b
 }
 
+func (b *BadgeBuilder) SetLabelColor(labelColor string) *BadgeBuilder {
+	b.LabelColor = labelColor
+	return b
+}
+
+func (b *BadgeBuilder) SetLogo(logo string) *BadgeBuilder {
+	b.Logo = logo
+	return b
+}
+
+func (b *BadgeBuilder) SetLogoSVG(logoSVG string) *BadgeBuilder {
+	b.LogoSVG = logoSVG
+	return b
+}
+
 func (b *BadgeBuilder) SetStyle(style string) *BadgeBuilder {
 	b.Style = style
 	return b
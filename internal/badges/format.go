@@ -0,0 +1,78 @@
+package badges
+
+import (
+	"mime"
+	"strconv"
+	"strings"
+)
+
+const (
+	FormatSVG      string = "svg"
+	FormatPNG      string = "png"
+	FormatText     string = "txt"
+	FormatJSON     string = "json"
+	FormatEndpoint string = "endpoint"
+)
+
+// acceptFormats maps the media types understood in an Accept header to
+// their format. The shields endpoint format has no media type of its own and
+// is only available through the type query parameter.
+var acceptFormats = map[string]string{
+	"image/svg+xml":    FormatSVG,
+	"image/png":        FormatPNG,
+	"text/plain":       FormatText,
+	"application/json": FormatJSON,
+}
+
+// EndpointBadge is the shields.io endpoint badge schema, see
+// https://shields.io/badges/endpoint-badge.
+type EndpointBadge struct {
+	SchemaVersion int    `json:"schemaVersion"`
+	Label         string `json:"label"`
+	Message       string `json:"message"`
+	Color         string `json:"color"`
+	LabelColor    string `json:"labelColor,omitempty"`
+	NamedLogo     string `json:"namedLogo,omitempty"`
+	LogoSVG       string `json:"logoSvg,omitempty"`
+	Style         string `json:"style,omitempty"`
+	CacheSeconds  int    `json:"cacheSeconds,omitempty"`
+	IsError       bool   `json:"isError,omitempty"`
+}
+
+// NegotiateFormat picks the output format of a badge. An explicit type query
+// parameter wins, and unknown values fall back to SVG as before. Without it,
+// the Accept media type with the highest quality is used, preferring SVG
+// among equal qualities, and wildcards or unknown types select SVG.
+func NegotiateFormat(resultType, accept string) string {
+	switch resultType {
+	case FormatSVG, FormatPNG, FormatText, FormatJSON, FormatEndpoint:
+		return resultType
+	case "":
+	default:
+		return FormatSVG
+	}
+
+	format := FormatSVG
+	bestQuality := 0.0
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		value, ok := acceptFormats[mediaType]
+		if !ok {
+			continue
+		}
+		quality := 1.0
+		if q, err := strconv.ParseFloat(params["q"], 64); err == nil {
+			quality = q
+		}
+		// browsers list several image types at the same quality, and SVG
+		// is the format badges are drawn in
+		if quality > bestQuality || (quality == bestQuality && quality > 0 && value == FormatSVG) {
+			format = value
+			bestQuality = quality
+		}
+	}
+	return format
+}
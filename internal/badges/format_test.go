@@ -0,0 +1,59 @@
+package badges
+
+import (
+	"bytes"
+	"image/png"
+	"testing"
+)
+
+func TestNegotiateFormat(t *testing.T) {
+	testCases := []struct {
+		name       string
+		resultType string
+		accept     string
+		expected   string
+	}{
+		{name: "default", expected: FormatSVG},
+		{name: "type wins over accept", resultType: FormatEndpoint, accept: "image/png", expected: FormatEndpoint},
+		{name: "unknown type", resultType: "gif", accept: "image/png", expected: FormatSVG},
+		{name: "accept png", accept: "image/png", expected: FormatPNG},
+		{name: "accept text", accept: "text/plain; charset=utf-8", expected: FormatText},
+		{name: "accept quality", accept: "application/json;q=0.5, image/png;q=0.9", expected: FormatPNG},
+		{name: "browser image", accept: "image/avif,image/webp,image/apng,image/svg+xml,image/*,*/*;q=0.8", expected: FormatSVG},
+		{name: "firefox image", accept: "image/avif,image/webp,image/png,image/svg+xml,image/*;q=0.8,*/*;q=0.5", expected: FormatSVG},
+		{name: "equal quality without svg", accept: "text/plain,image/png", expected: FormatText},
+		{name: "svg refused", accept: "image/png,image/svg+xml;q=0", expected: FormatPNG},
+		{name: "wildcard", accept: "*/*", expected: FormatSVG},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := NegotiateFormat(tc.resultType, tc.accept)
+			if actual != tc.expected {
+				t.Errorf("Expected %q, but got %q", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestRenderPNG(t *testing.T) {
+	data, err := NewBadgeBuilder().SetLabel("404").SetMessage("badge not found").SetMessageColor(Red).Build().RenderPNG()
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bounds := img.Bounds()
+	if bounds.Dy() != pngHeight || bounds.Dx() < 80 {
+		t.Errorf("Unexpected size %v", bounds)
+	}
+	if _, _, _, a := img.At(0, 0).RGBA(); a != 0 {
+		t.Errorf("Expected a transparent corner, got alpha %d", a)
+	}
+	if r, g, b, _ := img.At(bounds.Max.X-2, bounds.Max.Y/2).RGBA(); r>>8 != 0xe0 || g>>8 != 0x5d || b>>8 != 0x44 {
+		t.Errorf("Expected the red message background, got %d %d %d", r>>8, g>>8, b>>8)
+	}
+}
@@ -0,0 +1,66 @@
+package badges
+
+import "unicode"
+
+// verdana11 holds the advance widths, in pixels, of the printable ASCII
+// characters rendered in 11px Verdana. DejaVu Sans, the usual fallback on
+// Linux, has near-identical metrics, so the same table is used for both.
+var verdana11 = [...]float64{
+	3.87, 4.33, 5.05, 9.00, 6.99, 11.84, 7.99, 2.95, // space ! " # $ % & '
+	4.99, 4.99, 6.99, 9.00, 4.00, 4.99, 4.00, 4.99, // ( ) * + , - . /
+	6.99, 6.99, 6.99, 6.99, 6.99, 6.99, 6.99, 6.99, // 0-7
+	6.99, 6.99, 4.99, 4.99, 9.00, 9.00, 9.00, 6.00, // 8 9 : ; < = > ?
+	11.00, 7.52, 7.54, 7.68, 8.48, 6.96, 6.32, 8.53, // @ A-G
+	8.27, 4.63, 5.00, 7.62, 6.12, 9.27, 8.23, 8.66, // H-O
+	6.63, 8.66, 7.65, 7.52, 6.78, 8.05, 7.52, 10.88, // P-W
+	7.54, 6.77, 7.54, 4.99, 4.99, 4.99, 9.00, 6.99, // X Y Z [ \ ] ^ _
+	6.99, 6.61, 6.85, 5.73, 6.85, 6.55, 3.87, 6.85, // ` a-g
+	6.96, 3.02, 3.79, 6.51, 3.02, 10.70, 6.96, 6.68, // h-o
+	6.85, 6.85, 4.69, 5.73, 4.33, 6.96, 6.51, 8.98, // p-w
+	6.51, 6.51, 5.78, 6.98, 4.99, 6.98, 9.00, // x y z { | } ~
+}
+
+const (
+	// fallbackWidth is used for characters missing from the table.
+	fallbackWidth = 6.68
+	// wideWidth is used for East Asian wide characters, which occupy a
+	// full em.
+	wideWidth = 11.00
+	// boldFactor approximates the extra advance of Verdana Bold over
+	// Verdana Regular.
+	boldFactor = 1.1
+	// helveticaFactor approximates Helvetica Bold, used by the social
+	// style, relative to Verdana Regular.
+	helveticaFactor = 0.95
+)
+
+func charWidth(r rune) float64 {
+	if r >= ' ' && r <= '~' {
+		return verdana11[r-' ']
+	}
+	if unicode.Is(unicode.Han, r) || unicode.Is(unicode.Hangul, r) ||
+		unicode.Is(unicode.Hiragana, r) || unicode.Is(unicode.Katakana, r) {
+		return wideWidth
+	}
+	return fallbackWidth
+}
+
+// textWidth returns the width of s in pixels when rendered in Verdana at
+// the given font size.
+func textWidth(s string, fontSize float64) float64 {
+	width := 0.0
+	for _, r := range s {
+		width += charWidth(r)
+	}
+	return width * fontSize / 11
+}
+
+// preferredWidth truncates the width to an integer and rounds it up to the
+// next odd number, which keeps the text centred on the pixel grid.
+func preferredWidth(width float64) int {
+	w := int(width)
+	if w%2 == 0 {
+		w++
+	}
+	return w
+}
@@ -0,0 +1,121 @@
+package badges
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"strconv"
+	"strings"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+const (
+	pngHeight   = 20
+	pngBaseline = 14
+	pngRadius   = 3
+)
+
+// pngFont is the Go font bundled with x/image. Verdana cannot be shipped, so
+// PNG badges measure their text with this font instead of the Verdana table.
+var pngFont, _ = opentype.Parse(goregular.TTF)
+
+// RenderPNG rasterizes the badge for clients that cannot display SVG, such
+// as chat tools and mail clients. The flat-square and for-the-badge styles
+// get square corners, every other style is drawn as flat. Logos are left
+// out.
+func (b *BadgeBuilder) RenderPNG() ([]byte, error) {
+	// a Face is not safe for concurrent use, so each render creates its own
+	face, err := opentype.NewFace(pngFont, &opentype.FaceOptions{
+		Size:    11,
+		DPI:     72,
+		Hinting: font.HintingFull,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer face.Close()
+
+	labelColor := parseHexColor(ResolveColor(b.LabelColor, defaultLabelColor))
+	messageColor := parseHexColor(ResolveColor(b.MessageColor, namedColors[Blue]))
+
+	labelText := font.MeasureString(face, b.Label).Ceil()
+	messageText := font.MeasureString(face, b.Message).Ceil()
+	labelWidth := labelText + 2*horizPadding
+	totalWidth := labelWidth + messageText + 2*horizPadding
+
+	img := image.NewRGBA(image.Rect(0, 0, totalWidth, pngHeight))
+	draw.Draw(img, image.Rect(0, 0, labelWidth, pngHeight), image.NewUniform(labelColor), image.Point{}, draw.Src)
+	draw.Draw(img, image.Rect(labelWidth, 0, totalWidth, pngHeight), image.NewUniform(messageColor), image.Point{}, draw.Src)
+
+	drawPNGText(img, face, b.Label, horizPadding, ResolveColor(b.LabelColor, defaultLabelColor))
+	drawPNGText(img, face, b.Message, labelWidth+horizPadding, ResolveColor(b.MessageColor, namedColors[Blue]))
+
+	if b.Style != StyleFlatSquare && b.Style != StyleForTheBadge {
+		roundCorners(img, pngRadius)
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// drawPNGText draws text with a one pixel shadow, in the colors the SVG
+// renderer picks for the background.
+func drawPNGText(img draw.Image, face font.Face, text string, x int, background string) {
+	fill, shadow := textColors(background)
+	drawer := font.Drawer{Dst: img, Face: face}
+
+	drawer.Src = image.NewUniform(withAlpha(parseHexColor(shadow), 0x4d))
+	drawer.Dot = fixed.P(x, pngBaseline+1)
+	drawer.DrawString(text)
+
+	drawer.Src = image.NewUniform(parseHexColor(fill))
+	drawer.Dot = fixed.P(x, pngBaseline)
+	drawer.DrawString(text)
+}
+
+// roundCorners clears the pixels outside a quarter circle in each corner.
+func roundCorners(img *image.RGBA, radius int) {
+	bounds := img.Bounds()
+	r2 := radius * radius
+	for dy := 0; dy < radius; dy++ {
+		for dx := 0; dx < radius; dx++ {
+			x, y := radius-dx, radius-dy
+			if x*x+y*y <= r2 {
+				continue
+			}
+			img.Set(bounds.Min.X+dx, bounds.Min.Y+dy, color.Transparent)
+			img.Set(bounds.Max.X-1-dx, bounds.Min.Y+dy, color.Transparent)
+			img.Set(bounds.Min.X+dx, bounds.Max.Y-1-dy, color.Transparent)
+			img.Set(bounds.Max.X-1-dx, bounds.Max.Y-1-dy, color.Transparent)
+		}
+	}
+}
+
+// parseHexColor parses a #rgb or #rrggbb color as returned by ResolveColor.
+func parseHexColor(hex string) color.RGBA {
+	hex = strings.TrimPrefix(hex, "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	value, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil || len(hex) != 6 {
+		return color.RGBA{A: 0xff}
+	}
+	return color.RGBA{R: uint8(value >> 16), G: uint8(value >> 8), B: uint8(value), A: 0xff}
+}
+
+// withAlpha returns the color with the given opacity, premultiplied as
+// color.RGBA requires.
+func withAlpha(c color.RGBA, alpha uint8) color.RGBA {
+	scale := func(v uint8) uint8 { return uint8(uint16(v) * uint16(alpha) / 0xff) }
+	return color.RGBA{R: scale(c.R), G: scale(c.G), B: scale(c.B), A: alpha}
+}
@@ -0,0 +1,303 @@
+package badges
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	StyleFlat        string = "flat"
+	StyleFlatSquare  string = "flat-square"
+	StylePlastic     string = "plastic"
+	StyleForTheBadge string = "for-the-badge"
+	StyleSocial      string = "social"
+)
+
+const (
+	defaultLabelColor = "#555"
+	horizPadding      = 5
+	logoWidth         = 14
+	logoPadding       = 3
+	fontFamily        = "Verdana,Geneva,DejaVu Sans,sans-serif"
+)
+
+// namedColors mirrors the color names understood by shields.io, so badges
+// look the same whichever backend renders them.
+var namedColors = map[string]string{
+	"brightgreen":   "#4c1",
+	"green":         "#97ca00",
+	"yellow":        "#dfb317",
+	"yellowgreen":   "#a4a61d",
+	"orange":        "#fe7d37",
+	"red":           "#e05d44",
+	"blue":          "#007ec6",
+	"grey":          "#555",
+	"gray":          "#555",
+	"lightgrey":     "#9f9f9f",
+	"lightgray":     "#9f9f9f",
+	"success":       "#4c1",
+	"important":     "#fe7d37",
+	"critical":      "#e05d44",
+	"informational": "#007ec6",
+	"inactive":      "#9f9f9f",
+}
+
+var xmlEscaper = strings.NewReplacer(
+	"&", "&amp;",
+	"<", "&lt;",
+	">", "&gt;",
+	`"`, "&quot;",
+	"'", "&apos;",
+)
+
+// ResolveColor turns a shields color name or a hex value, with or without
+// the leading '#', into a CSS color. Unknown values fall back to def.
+func ResolveColor(color string, def string) string {
+	color = strings.TrimSpace(strings.ToLower(color))
+	if value, ok := namedColors[color]; ok {
+		return value
+	}
+	hex := strings.TrimPrefix(color, "#")
+	if len(hex) == 3 || len(hex) == 6 {
+		if _, err := strconv.ParseUint(hex, 16, 32); err == nil {
+			return "#" + hex
+		}
+	}
+	return def
+}
+
+// textColors returns the text and shadow colors giving the best contrast on
+// the given background.
+func textColors(background string) (string, string) {
+	hex := strings.TrimPrefix(background, "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	value, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil || len(hex) != 6 {
+		return "#fff", "#010101"
+	}
+	r := float64(value>>16&0xff) / 255
+	g := float64(value>>8&0xff) / 255
+	b := float64(value&0xff) / 255
+	if 0.299*r+0.587*g+0.114*b > 0.69 {
+		return "#333", "#ccc"
+	}
+	return "#fff", "#010101"
+}
+
+// logoHref returns an SVG logo as a data URI. The logo is drawn by an image
+// element, where browsers neither run its scripts nor load its resources.
+func logoHref(svg string) string {
+	if len(svg) == 0 {
+		return ""
+	}
+	return "data:image/svg+xml;base64," + base64.StdEncoding.EncodeToString([]byte(svg))
+}
+
+// RenderSVG renders the badge as an SVG document in the configured style.
+// Unknown styles are rendered as flat. Named logos need the simple-icons set
+// of shields.io, so only LogoSVG is drawn, in the flat, flat-square and
+// plastic styles.
+func (b *BadgeBuilder) RenderSVG() []byte {
+	labelColor := ResolveColor(b.LabelColor, defaultLabelColor)
+	messageColor := ResolveColor(b.MessageColor, namedColors[Blue])
+	logo := logoHref(b.LogoSVG)
+
+	switch b.Style {
+	case StyleFlatSquare:
+		return renderFlatSquare(b.Label, b.Message, labelColor, messageColor, logo)
+	case StylePlastic:
+		return renderPlastic(b.Label, b.Message, labelColor, messageColor, logo)
+	case StyleForTheBadge:
+		return renderForTheBadge(b.Label, b.Message, labelColor, messageColor)
+	case StyleSocial:
+		return renderSocial(b.Label, b.Message)
+	default:
+		return renderFlat(b.Label, b.Message, labelColor, messageColor, logo)
+	}
+}
+
+// layout holds the geometry shared by the flat, flat-square and plastic
+// styles. Text coordinates are multiplied by ten, as the text is drawn with
+// scale(.1) for sub-pixel precision. A logo is drawn left of the label.
+type layout struct {
+	labelWidth   int
+	messageWidth int
+	totalWidth   int
+	labelX       int
+	labelLength  int
+	messageX     int
+	messageLen   int
+}
+
+func newLayout(label, message string, fontSize float64, logo string) layout {
+	labelText := preferredWidth(textWidth(label, fontSize))
+	messageText := preferredWidth(textWidth(message, fontSize))
+	logoSpace := 0
+	if len(logo) > 0 {
+		logoSpace = logoWidth + logoPadding
+	}
+
+	l := layout{
+		labelWidth:   labelText + 2*horizPadding + logoSpace,
+		messageWidth: messageText + 2*horizPadding,
+		labelLength:  labelText * 10,
+		messageLen:   messageText * 10,
+	}
+	l.totalWidth = l.labelWidth + l.messageWidth
+	l.labelX = (horizPadding+1+logoSpace)*10 + labelText*5
+	l.messageX = (l.labelWidth-1+horizPadding)*10 + messageText*5
+	return l
+}
+
+func writeHeader(sb *strings.Builder, width, height int, label, message string) {
+	title := xmlEscaper.Replace(label + ": " + message)
+	fmt.Fprintf(sb, `<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink" width="%d" height="%d" role="img" aria-label="%s">`, width, height, title)
+	fmt.Fprintf(sb, `<title>%s</title>`, title)
+}
+
+func writeLogo(sb *strings.Builder, logo string, height int) {
+	if len(logo) > 0 {
+		fmt.Fprintf(sb, `<image x="%d" y="%d" width="%d" height="%d" xlink:href="%s"/>`, horizPadding, (height-logoWidth)/2, logoWidth, logoWidth, logo)
+	}
+}
+
+func writeText(sb *strings.Builder, text string, x, y, length int, fill, shadow string, shadowOffset int) {
+	text = xmlEscaper.Replace(text)
+	if shadow != "" {
+		fmt.Fprintf(sb, `<text aria-hidden="true" x="%d" y="%d" fill="%s" fill-opacity=".3" transform="scale(.1)" textLength="%d">%s</text>`, x, y+shadowOffset, shadow, length, text)
+	}
+	fmt.Fprintf(sb, `<text x="%d" y="%d" transform="scale(.1)" fill="%s" textLength="%d">%s</text>`, x, y, fill, length, text)
+}
+
+func renderFlat(label, message, labelColor, messageColor, logo string) []byte {
+	l := newLayout(label, message, 11, logo)
+	labelFill, labelShadow := textColors(labelColor)
+	messageFill, messageShadow := textColors(messageColor)
+
+	var sb strings.Builder
+	writeHeader(&sb, l.totalWidth, 20, label, message)
+	sb.WriteString(`<linearGradient id="s" x2="0" y2="100%"><stop offset="0" stop-color="#bbb" stop-opacity=".1"/><stop offset="1" stop-opacity=".1"/></linearGradient>`)
+	fmt.Fprintf(&sb, `<clipPath id="r"><rect width="%d" height="20" rx="3" fill="#fff"/></clipPath>`, l.totalWidth)
+	fmt.Fprintf(&sb, `<g clip-path="url(#r)"><rect width="%d" height="20" fill="%s"/><rect x="%d" width="%d" height="20" fill="%s"/><rect width="%d" height="20" fill="url(#s)"/></g>`,
+		l.labelWidth, labelColor, l.labelWidth, l.messageWidth, messageColor, l.totalWidth)
+	writeLogo(&sb, logo, 20)
+	fmt.Fprintf(&sb, `<g fill="#fff" text-anchor="middle" font-family="%s" text-rendering="geometricPrecision" font-size="110">`, fontFamily)
+	writeText(&sb, label, l.labelX, 140, l.labelLength, labelFill, labelShadow, 10)
+	writeText(&sb, message, l.messageX, 140, l.messageLen, messageFill, messageShadow, 10)
+	sb.WriteString(`</g></svg>`)
+	return []byte(sb.String())
+}
+
+func renderFlatSquare(label, message, labelColor, messageColor, logo string) []byte {
+	l := newLayout(label, message, 11, logo)
+	labelFill, _ := textColors(labelColor)
+	messageFill, _ := textColors(messageColor)
+
+	var sb strings.Builder
+	writeHeader(&sb, l.totalWidth, 20, label, message)
+	fmt.Fprintf(&sb, `<g shape-rendering="crispEdges"><rect width="%d" height="20" fill="%s"/><rect x="%d" width="%d" height="20" fill="%s"/></g>`,
+		l.labelWidth, labelColor, l.labelWidth, l.messageWidth, messageColor)
+	writeLogo(&sb, logo, 20)
+	fmt.Fprintf(&sb, `<g fill="#fff" text-anchor="middle" font-family="%s" text-rendering="geometricPrecision" font-size="110">`, fontFamily)
+	writeText(&sb, label, l.labelX, 140, l.labelLength, labelFill, "", 0)
+	writeText(&sb, message, l.messageX, 140, l.messageLen, messageFill, "", 0)
+	sb.WriteString(`</g></svg>`)
+	return []byte(sb.String())
+}
+
+func renderPlastic(label, message, labelColor, messageColor, logo string) []byte {
+	l := newLayout(label, message, 11, logo)
+	labelFill, labelShadow := textColors(labelColor)
+	messageFill, messageShadow := textColors(messageColor)
+
+	var sb strings.Builder
+	writeHeader(&sb, l.totalWidth, 18, label, message)
+	sb.WriteString(`<linearGradient id="s" x2="0" y2="100%"><stop offset="0" stop-color="#fff" stop-opacity=".7"/><stop offset=".1" stop-color="#aaa" stop-opacity=".1"/><stop offset=".9" stop-opacity=".3"/><stop offset="1" stop-opacity=".5"/></linearGradient>`)
+	fmt.Fprintf(&sb, `<clipPath id="r"><rect width="%d" height="18" rx="4" fill="#fff"/></clipPath>`, l.totalWidth)
+	fmt.Fprintf(&sb, `<g clip-path="url(#r)"><rect width="%d" height="18" fill="%s"/><rect x="%d" width="%d" height="18" fill="%s"/><rect width="%d" height="18" fill="url(#s)"/></g>`,
+		l.labelWidth, labelColor, l.labelWidth, l.messageWidth, messageColor, l.totalWidth)
+	writeLogo(&sb, logo, 18)
+	fmt.Fprintf(&sb, `<g fill="#fff" text-anchor="middle" font-family="%s" text-rendering="geometricPrecision" font-size="110">`, fontFamily)
+	writeText(&sb, label, l.labelX, 130, l.labelLength, labelFill, labelShadow, 10)
+	writeText(&sb, message, l.messageX, 130, l.messageLen, messageFill, messageShadow, 10)
+	sb.WriteString(`</g></svg>`)
+	return []byte(sb.String())
+}
+
+func renderForTheBadge(label, message, labelColor, messageColor string) []byte {
+	const (
+		textMargin    = 12
+		letterSpacing = 1.25
+	)
+	label = strings.ToUpper(label)
+	message = strings.ToUpper(message)
+
+	labelText := int(textWidth(label, 10) + letterSpacing*float64(utf8.RuneCountInString(label)))
+	messageText := int(textWidth(message, 10)*boldFactor + letterSpacing*float64(utf8.RuneCountInString(message)))
+	labelWidth := labelText + 2*textMargin
+	messageWidth := messageText + 2*textMargin
+	if len(label) == 0 {
+		labelWidth = 0
+	}
+	totalWidth := labelWidth + messageWidth
+
+	labelFill, _ := textColors(labelColor)
+	messageFill, _ := textColors(messageColor)
+
+	var sb strings.Builder
+	writeHeader(&sb, totalWidth, 28, label, message)
+	fmt.Fprintf(&sb, `<g shape-rendering="crispEdges"><rect width="%d" height="28" fill="%s"/><rect x="%d" width="%d" height="28" fill="%s"/></g>`,
+		labelWidth, labelColor, labelWidth, messageWidth, messageColor)
+	fmt.Fprintf(&sb, `<g fill="#fff" text-anchor="middle" font-family="%s" text-rendering="geometricPrecision" font-size="100">`, fontFamily)
+	if labelWidth > 0 {
+		fmt.Fprintf(&sb, `<text transform="scale(.1)" x="%d" y="175" textLength="%d" fill="%s">%s</text>`,
+			labelWidth*5, labelText*10, labelFill, xmlEscaper.Replace(label))
+	}
+	fmt.Fprintf(&sb, `<text transform="scale(.1)" x="%d" y="175" textLength="%d" fill="%s" font-weight="bold">%s</text>`,
+		(labelWidth*2+messageWidth)*5, messageText*10, messageFill, xmlEscaper.Replace(message))
+	sb.WriteString(`</g></svg>`)
+	return []byte(sb.String())
+}
+
+func renderSocial(label, message string) []byte {
+	const arrowWidth = 6
+
+	if r, size := utf8.DecodeRuneInString(label); size > 0 {
+		label = string(unicode.ToUpper(r)) + label[size:]
+	}
+
+	labelText := preferredWidth(textWidth(label, 11) * helveticaFactor * boldFactor)
+	messageText := preferredWidth(textWidth(message, 11) * helveticaFactor * boldFactor)
+	labelWidth := labelText + 2*horizPadding + 1
+	messageWidth := messageText + 2*horizPadding
+	totalWidth := labelWidth + arrowWidth + messageWidth + 1
+	messageLeft := labelWidth + arrowWidth
+
+	var sb strings.Builder
+	writeHeader(&sb, totalWidth, 20, label, message)
+	sb.WriteString(`<style>a:hover #llink{fill:url(#b);stroke:#ccc}a:hover #rlink{fill:#4183c4}</style>`)
+	sb.WriteString(`<linearGradient id="a" x2="0" y2="100%"><stop offset="0" stop-color="#fcfcfc" stop-opacity="0"/><stop offset="1" stop-opacity=".1"/></linearGradient>`)
+	sb.WriteString(`<linearGradient id="b" x2="0" y2="100%"><stop offset="0" stop-color="#ccc" stop-opacity=".1"/><stop offset="1" stop-opacity=".1"/></linearGradient>`)
+	fmt.Fprintf(&sb, `<g stroke="#d5d5d5"><rect stroke="none" fill="#fcfcfc" x="0.5" y="0.5" width="%d" height="19" rx="2"/>`, labelWidth-1)
+	fmt.Fprintf(&sb, `<rect x="%d.5" y="0.5" width="%d" height="19" rx="2" fill="#fafafa"/>`, messageLeft, messageWidth)
+	fmt.Fprintf(&sb, `<rect x="%d" y="7.5" width="0.5" height="5" stroke="#fafafa"/>`, messageLeft)
+	fmt.Fprintf(&sb, `<path d="M%d.5 6.5 l-3 3v1 l3 3" stroke="#d5d5d5" fill="#fafafa"/></g>`, messageLeft)
+	sb.WriteString(`<g aria-hidden="true" fill="#333" text-anchor="middle" font-family="Helvetica Neue,Helvetica,Arial,sans-serif" text-rendering="geometricPrecision" font-weight="700" font-size="110px" line-height="14px">`)
+	fmt.Fprintf(&sb, `<rect id="llink" stroke="#d5d5d5" fill="url(#a)" x=".5" y=".5" width="%d" height="19" rx="2"/>`, labelWidth-1)
+	labelX := (labelWidth/2)*10 + 5
+	messageX := (messageLeft+messageWidth/2)*10 + 5
+	escapedLabel := xmlEscaper.Replace(label)
+	escapedMessage := xmlEscaper.Replace(message)
+	fmt.Fprintf(&sb, `<text aria-hidden="true" x="%d" y="150" fill="#fff" transform="scale(.1)" textLength="%d">%s</text>`, labelX, labelText*10, escapedLabel)
+	fmt.Fprintf(&sb, `<text x="%d" y="140" transform="scale(.1)" textLength="%d">%s</text>`, labelX, labelText*10, escapedLabel)
+	fmt.Fprintf(&sb, `<text aria-hidden="true" x="%d" y="150" fill="#fff" transform="scale(.1)" textLength="%d">%s</text>`, messageX, messageText*10, escapedMessage)
+	fmt.Fprintf(&sb, `<text id="rlink" x="%d" y="140" transform="scale(.1)" textLength="%d">%s</text>`, messageX, messageText*10, escapedMessage)
+	sb.WriteString(`</g></svg>`)
+	return []byte(sb.String())
+}
@@ -0,0 +1,130 @@
+package badges
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPreferredWidth(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected int
+	}{
+		{input: "404", expected: 21},
+		{input: "Unauthorized", expected: 73},
+		{input: "badge not found", expected: 91},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			actual := preferredWidth(textWidth(tc.input, 11))
+			if actual != tc.expected {
+				t.Errorf("Expected %d, but got %d", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestResolveColor(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected string
+	}{
+		{input: "green", expected: "#97ca00"},
+		{input: "Red", expected: "#e05d44"},
+		{input: "ff0000", expected: "#ff0000"},
+		{input: "#abc", expected: "#abc"},
+		{input: "not-a-color", expected: "#007ec6"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			actual := ResolveColor(tc.input, namedColors[Blue])
+			if actual != tc.expected {
+				t.Errorf("Expected %q, but got %q", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestRenderSVG(t *testing.T) {
+	testCases := []struct {
+		style    string
+		contains []string
+	}{
+		{
+			style: StyleFlat,
+			contains: []string{
+				`width="114" height="20"`,
+				`<rect width="31" height="20" fill="#555"/>`,
+				`<rect x="31" width="83" height="20" fill="#e05d44"/>`,
+				`x="165" y="140" transform="scale(.1)" fill="#fff" textLength="210">404</text>`,
+				`x="715" y="140" transform="scale(.1)" fill="#fff" textLength="730">Unauthorized</text>`,
+			},
+		},
+		{
+			style:    StyleFlatSquare,
+			contains: []string{`shape-rendering="crispEdges"`, `width="114" height="20"`},
+		},
+		{
+			style:    StylePlastic,
+			contains: []string{`height="18"`, `rx="4"`},
+		},
+		{
+			style:    StyleForTheBadge,
+			contains: []string{`height="28"`, `>UNAUTHORIZED</text>`},
+		},
+		{
+			style:    StyleSocial,
+			contains: []string{`id="llink"`, `>Unauthorized</text>`},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.style, func(t *testing.T) {
+			svg := string(NewBadgeBuilder().
+				SetLabel("404").
+				SetMessage("Unauthorized").
+				SetMessageColor(Red).
+				SetStyle(tc.style).
+				Build().
+				RenderSVG())
+			for _, want := range tc.contains {
+				if !strings.Contains(svg, want) {
+					t.Errorf("Expected %q in %s", want, svg)
+				}
+			}
+		})
+	}
+}
+
+func TestRenderSVGEscapesText(t *testing.T) {
+	svg := string(NewBadgeBuilder().SetLabel("a<b").SetMessage(`"c"&d`).Build().RenderSVG())
+	if strings.Contains(svg, "a<b") || !strings.Contains(svg, "a&lt;b") || !strings.Contains(svg, "&quot;c&quot;&amp;d") {
+		t.Errorf("Expected escaped text in %s", svg)
+	}
+}
+
+func TestRenderSVGAppearance(t *testing.T) {
+	plain := string(NewBadgeBuilder().SetLabel("404").SetMessage("Unauthorized").Build().RenderSVG())
+	svg := string(NewBadgeBuilder().
+		SetLabel("404").
+		SetMessage("Unauthorized").
+		SetLabelColor("#ffffff").
+		SetLogoSVG(`<svg xmlns="http://www.w3.org/2000/svg"/>`).
+		Build().
+		RenderSVG())
+
+	for _, want := range []string{
+		`<rect width="48" height="20" fill="#ffffff"/>`,
+		`<image x="5" y="3" width="14" height="14" xlink:href="data:image/svg+xml;base64,`,
+		`x="335" y="140" transform="scale(.1)" fill="#333"`,
+	} {
+		if !strings.Contains(svg, want) {
+			t.Errorf("Expected %q in %s", want, svg)
+		}
+	}
+	if strings.Contains(svg, "<svg xmlns=\"http://www.w3.org/2000/svg\"/>") || strings.Contains(plain, "<image") {
+		t.Errorf("Expected the logo only as a data URI, got %s", svg)
+	}
+}
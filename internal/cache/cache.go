@@ -2,6 +2,7 @@ package cache
 
 import (
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -10,11 +11,23 @@ type CacheEntry[T any] struct {
 	ExpiresAt time.Time
 }
 
+// Stats holds the counters of a cache since it was created.
+type Stats struct {
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64 // expired entries removed by Get or the cleanup
+	Size      int
+}
+
 type Cache[K comparable, V any] struct {
 	mu              sync.RWMutex
 	data            map[K]CacheEntry[V]
 	cleanupInterval time.Duration
 	stop            chan struct{}
+
+	hits      atomic.Uint64
+	misses    atomic.Uint64
+	evictions atomic.Uint64
 }
 
 func NewCache[K comparable, V any]() *Cache[K, V] {
@@ -43,16 +56,20 @@ func (c *Cache[K, V]) Get(key K) (V, bool) {
 	entry, exists := c.data[key]
 	if !exists {
 		c.mu.RUnlock()
+		c.misses.Add(1)
 		var zero V
 		return zero, false
 	}
 	if time.Now().After(entry.ExpiresAt) {
 		c.mu.RUnlock()
 		c.Delete(key)
+		c.misses.Add(1)
+		c.evictions.Add(1)
 		var zero V
 		return zero, false
 	}
 	c.mu.RUnlock()
+	c.hits.Add(1)
 	return entry.Value, true
 }
 
@@ -80,6 +97,7 @@ func (c *Cache[K, V]) cleanup() {
 			for k, v := range c.data {
 				if now.After(v.ExpiresAt) {
 					delete(c.data, k)
+					c.evictions.Add(1)
 				}
 			}
 			c.mu.Unlock()
@@ -89,6 +107,20 @@ func (c *Cache[K, V]) cleanup() {
 	}
 }
 
+// Stats returns the hit, miss and eviction counters and the current number
+// of entries.
+func (c *Cache[K, V]) Stats() Stats {
+	c.mu.RLock()
+	size := len(c.data)
+	c.mu.RUnlock()
+	return Stats{
+		Hits:      c.hits.Load(),
+		Misses:    c.misses.Load(),
+		Evictions: c.evictions.Load(),
+		Size:      size,
+	}
+}
+
 func (c *Cache[K, V]) Stop() {
 	close(c.stop)
 }
@@ -79,3 +79,20 @@ func TestCache_Expire(t *testing.T) {
 		t.Errorf("Expected key 'foo' to be expired, but it isn't")
 	}
 }
+
+func TestCache_Stats(t *testing.T) {
+	cache := NewCache[string, int]()
+
+	cache.Set("foo", 42, time.Minute)
+	cache.Set("bar", 1, time.Millisecond)
+	time.Sleep(time.Millisecond * 2)
+
+	cache.Get("foo")
+	cache.Get("bar")
+	cache.Get("baz")
+
+	stats := cache.Stats()
+	if stats.Hits != 1 || stats.Misses != 2 || stats.Evictions != 1 || stats.Size != 1 {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+}
@@ -1,8 +1,38 @@
 package config
 
 type Config struct {
+	BadgeBackend   string
 	ShieldsHost    string
 	ShieldsScheme  string
 	CacheTime      int
 	BadgeCacheTime int
+	CronJobMaxAge  int
+
+	NotifyWebhookURLs []string
+	NotifySlackURLs   []string
+	NotifyTeamsURLs   []string
+	NotifyInterval    int
+	NotifyDebounce    int
+
+	HistoryInterval int
+
+	OrphanInterval    int
+	OrphanGracePeriod int
+
+	StatusInterval  int
+	StatusWriteRate int
+
+	StreamExternal bool
+
+	SigningKey string
+
+	AuthTokenFile          string
+	AuthTokenReview        bool
+	AuthOIDCIssuerURL      string
+	AuthOIDCClientID       string
+	AuthOIDCUsernameClaim  string
+	AuthOIDCGroupsClaim    string
+	AuthOIDCUsernamePrefix string
+	AuthOIDCGroupsPrefix   string
+	AuthAuthorize          bool
 }
@@ -6,3 +6,18 @@ const (
 	KubeBadgeCRDKind       = "KubeBadge"
 	KubeBadgeCRDAPIVersion = "kubebadges.tcode.ltd/v1"
 )
+
+// KubeBadgeHistoryName is the ConfigMap holding the status history of the
+// badges, one data key per KubeBadge.
+const KubeBadgeHistoryName = "kubebadge-history"
+
+// KubeBadgeConfigCustomResourcesKey is the kubebadge-config key holding the
+// YAML list of custom resource badge definitions.
+const KubeBadgeConfigCustomResourcesKey = "custom_resources"
+
+const (
+	// BadgeBackendNative renders badges in-process.
+	BadgeBackendNative = "native"
+	// BadgeBackendShields reverse-proxies badges to a shields.io server.
+	BadgeBackendShields = "shields"
+)
@@ -0,0 +1,38 @@
+package k8s
+
+import (
+	"context"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ReviewToken asks the API server whom a bearer token, such as a
+// ServiceAccount token, belongs to.
+func (k *KubeHelper) ReviewToken(ctx context.Context, token string) (authenticationv1.TokenReviewStatus, error) {
+	review, err := k.client.AuthenticationV1().TokenReviews().Create(ctx, &authenticationv1.TokenReview{
+		Spec: authenticationv1.TokenReviewSpec{Token: token},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return authenticationv1.TokenReviewStatus{}, err
+	}
+	return review.Status, nil
+}
+
+// ReviewAccess asks the API server whether the user may perform the action
+// described by attributes.
+func (k *KubeHelper) ReviewAccess(ctx context.Context, user, uid string, groups []string, attributes authorizationv1.ResourceAttributes) (bool, error) {
+	review, err := k.client.AuthorizationV1().SubjectAccessReviews().Create(ctx, &authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			User:               user,
+			UID:                uid,
+			Groups:             groups,
+			ResourceAttributes: &attributes,
+		},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return false, err
+	}
+	return review.Status.Allowed, nil
+}
@@ -0,0 +1,196 @@
+package k8s
+
+import (
+	"log/slog"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
+)
+
+// StartInformers starts shared informers for the kinds served as badges so
+// that badge handlers read from memory instead of calling the API server.
+// Custom resources are only watched when their API is installed. Until an
+// informer has synced, the getters fall back to direct API calls.
+func (k *KubeHelper) StartInformers(resync time.Duration) {
+	k.informerFactory = informers.NewSharedInformerFactory(k.client, resync)
+	k.dynamicInformerFactory = dynamicinformer.NewDynamicSharedInformerFactory(k.dynamicClient, resync)
+	k.dynamicResources = map[schema.GroupVersionResource]bool{}
+
+	for _, informer := range k.typedInformers() {
+		_ = informer.SetTransform(stripManagedFields)
+	}
+
+	k.informerFactory.Start(k.stopCh)
+
+	for _, gvr := range []schema.GroupVersionResource{kustomizationGVR, postgresqlGVR} {
+		k.WatchResource(gvr)
+	}
+}
+
+// WatchResource starts an informer for an arbitrary custom resource, such as
+// those configured for generic badges. It is a no-op when the resource is
+// already watched, not served by the cluster, or informers are not running.
+func (k *KubeHelper) WatchResource(gvr schema.GroupVersionResource) {
+	k.dynamicMu.Lock()
+	defer k.dynamicMu.Unlock()
+
+	if k.dynamicInformerFactory == nil || k.dynamicResources[gvr] {
+		return
+	}
+	if !k.isResourceServed(gvr) {
+		slog.Info("resource not served, skip informer", "resource", gvr.String())
+		return
+	}
+	informer := k.dynamicInformerFactory.ForResource(gvr).Informer()
+	_ = informer.SetTransform(stripManagedFields)
+	for _, handler := range k.changeHandlers {
+		_, _ = informer.AddEventHandler(handler)
+	}
+	k.dynamicResources[gvr] = true
+	k.dynamicInformerFactory.Start(k.stopCh)
+}
+
+// OnChange calls handler whenever an object of a watched kind is added,
+// updated or deleted, including custom resources watched later on.
+func (k *KubeHelper) OnChange(handler func()) {
+	eventHandler := cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(interface{}) { handler() },
+		UpdateFunc: func(interface{}, interface{}) { handler() },
+		DeleteFunc: func(interface{}) { handler() },
+	}
+
+	k.dynamicMu.Lock()
+	defer k.dynamicMu.Unlock()
+
+	k.changeHandlers = append(k.changeHandlers, eventHandler)
+	if k.informerFactory == nil {
+		return
+	}
+	for _, informer := range k.typedInformers() {
+		_, _ = informer.AddEventHandler(eventHandler)
+	}
+	for gvr := range k.dynamicResources {
+		_, _ = k.dynamicInformerFactory.ForResource(gvr).Informer().AddEventHandler(eventHandler)
+	}
+}
+
+// OnObjectChange calls handler with the object whenever a Namespace,
+// Deployment, StatefulSet, DaemonSet, Job or CronJob is added, updated or
+// deleted. The kind is named as in badge keys.
+func (k *KubeHelper) OnObjectChange(handler func(kind string, obj metav1.Object)) {
+	if k.informerFactory == nil {
+		return
+	}
+	informers := map[string]cache.SharedIndexInformer{
+		"namespace":   k.informerFactory.Core().V1().Namespaces().Informer(),
+		"deployment":  k.informerFactory.Apps().V1().Deployments().Informer(),
+		"statefulset": k.informerFactory.Apps().V1().StatefulSets().Informer(),
+		"daemonset":   k.informerFactory.Apps().V1().DaemonSets().Informer(),
+		"job":         k.informerFactory.Batch().V1().Jobs().Informer(),
+		"cronjob":     k.informerFactory.Batch().V1().CronJobs().Informer(),
+	}
+	for kind, informer := range informers {
+		kind := kind
+		notify := func(obj interface{}) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			if accessor, err := meta.Accessor(obj); err == nil {
+				handler(kind, accessor)
+			}
+		}
+		_, _ = informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+			AddFunc:    notify,
+			UpdateFunc: func(_, newObj interface{}) { notify(newObj) },
+			DeleteFunc: notify,
+		})
+	}
+}
+
+// InformersSynced reports whether every started informer has completed its
+// initial list.
+func (k *KubeHelper) InformersSynced() bool {
+	if k.informerFactory == nil {
+		return false
+	}
+	for _, informer := range k.typedInformers() {
+		if !informer.HasSynced() {
+			return false
+		}
+	}
+	k.dynamicMu.RLock()
+	defer k.dynamicMu.RUnlock()
+	for gvr := range k.dynamicResources {
+		if !k.dynamicInformerFactory.ForResource(gvr).Informer().HasSynced() {
+			return false
+		}
+	}
+	return true
+}
+
+func (k *KubeHelper) isResourceServed(gvr schema.GroupVersionResource) bool {
+	resources, err := k.client.Discovery().ServerResourcesForGroupVersion(gvr.GroupVersion().String())
+	if err != nil {
+		return false
+	}
+	for _, resource := range resources.APIResources {
+		if resource.Name == gvr.Resource {
+			return true
+		}
+	}
+	return false
+}
+
+// typedInformers returns the informers of the built-in kinds. The factory
+// creates each informer once, so repeated calls return the same instances.
+func (k *KubeHelper) typedInformers() []cache.SharedIndexInformer {
+	return []cache.SharedIndexInformer{
+		k.informerFactory.Core().V1().Nodes().Informer(),
+		k.informerFactory.Core().V1().Namespaces().Informer(),
+		k.informerFactory.Core().V1().Pods().Informer(),
+		k.informerFactory.Apps().V1().Deployments().Informer(),
+		k.informerFactory.Apps().V1().StatefulSets().Informer(),
+		k.informerFactory.Apps().V1().DaemonSets().Informer(),
+		k.informerFactory.Batch().V1().Jobs().Informer(),
+		k.informerFactory.Batch().V1().CronJobs().Informer(),
+	}
+}
+
+// getDynamic returns the cached object of a watched custom resource. The
+// boolean result is false when the resource is not watched or not synced yet.
+func (k *KubeHelper) getDynamic(gvr schema.GroupVersionResource, namespace, name string) (map[string]interface{}, bool, error) {
+	k.dynamicMu.RLock()
+	watched := k.dynamicResources[gvr]
+	k.dynamicMu.RUnlock()
+	if k.dynamicInformerFactory == nil || !watched {
+		return nil, false, nil
+	}
+	informer := k.dynamicInformerFactory.ForResource(gvr)
+	if !informer.Informer().HasSynced() {
+		return nil, false, nil
+	}
+	obj, err := informer.Lister().ByNamespace(namespace).Get(name)
+	if err != nil {
+		return nil, true, err
+	}
+	unstr, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return nil, false, nil
+	}
+	return unstr.Object, true, nil
+}
+
+// stripManagedFields drops managedFields before objects enter the informer
+// store; badges never read them and they dominate the memory footprint.
+func stripManagedFields(obj interface{}) (interface{}, error) {
+	if accessor, err := meta.Accessor(obj); err == nil {
+		accessor.SetManagedFields(nil)
+	}
+	return obj, nil
+}
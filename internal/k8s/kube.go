@@ -3,17 +3,25 @@ package k8s
 import (
 	"context"
 	"log/slog"
+	"net/http"
 	"os"
+	"sync"
 
+	"github.com/kubebadges/kubebadges/internal/metrics"
 	"github.com/kubebadges/kubebadges/pkg/generated/clientset/versioned"
-	batchv1 "k8s.io/api/batch/v1"
 	v1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/client-go/util/homedir"
 )
@@ -25,7 +33,7 @@ var (
 		Version:  "v1",
 		Resource: "kustomizations",
 	}
-	
+
 	postgresqlGVR = schema.GroupVersionResource{
 		Group:    "acid.zalan.do",
 		Version:  "v1",
@@ -37,10 +45,19 @@ type KubeHelper struct {
 	client          *kubernetes.Clientset
 	kubeBadgeClient *versioned.Clientset
 	dynamicClient   dynamic.Interface
+
+	informerFactory        informers.SharedInformerFactory
+	dynamicInformerFactory dynamicinformer.DynamicSharedInformerFactory
+	dynamicResources       map[schema.GroupVersionResource]bool // custom resources being watched
+	changeHandlers         []cache.ResourceEventHandler
+	dynamicMu              sync.RWMutex
+	stopCh                 chan struct{}
 }
 
 func NewKubeHelper() *KubeHelper {
-	return &KubeHelper{}
+	return &KubeHelper{
+		stopCh: make(chan struct{}),
+	}
 }
 
 func (k *KubeHelper) Init() {
@@ -57,6 +74,10 @@ func (k *KubeHelper) Init() {
 		}
 	}
 
+	config.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+		return metrics.InstrumentRoundTripper("kubernetes", rt)
+	})
+
 	// creates the clientset
 	clientset, err := kubernetes.NewForConfig(config)
 	if err != nil {
@@ -97,7 +118,15 @@ func (k *KubeHelper) GetNodes() ([]corev1.Node, error) {
 	return nodes.Items, nil
 }
 
+// GetNode returns the node from the informer cache when available. Objects
+// returned by the Get* helpers are shared and must not be modified.
 func (k *KubeHelper) GetNode(name string) (*corev1.Node, error) {
+	if k.informerFactory != nil {
+		if nodes := k.informerFactory.Core().V1().Nodes(); nodes.Informer().HasSynced() {
+			return nodes.Lister().Get(name)
+		}
+	}
+
 	node, err := k.client.CoreV1().Nodes().Get(context.Background(), name, metav1.GetOptions{})
 	if err != nil {
 		return nil, err
@@ -115,6 +144,12 @@ func (k *KubeHelper) GetNamespaces() ([]corev1.Namespace, error) {
 }
 
 func (k *KubeHelper) GetNamespace(name string) (*corev1.Namespace, error) {
+	if k.informerFactory != nil {
+		if namespaces := k.informerFactory.Core().V1().Namespaces(); namespaces.Informer().HasSynced() {
+			return namespaces.Lister().Get(name)
+		}
+	}
+
 	namespace, err := k.client.CoreV1().Namespaces().Get(context.Background(), name, metav1.GetOptions{})
 	if err != nil {
 		return nil, err
@@ -133,6 +168,12 @@ func (k *KubeHelper) GetDeployments(namespace string) ([]v1.Deployment, error) {
 }
 
 func (k *KubeHelper) GetDeployment(namespace string, name string) (*v1.Deployment, error) {
+	if k.informerFactory != nil {
+		if deployments := k.informerFactory.Apps().V1().Deployments(); deployments.Informer().HasSynced() {
+			return deployments.Lister().Deployments(namespace).Get(name)
+		}
+	}
+
 	deployment, err := k.client.AppsV1().Deployments(namespace).Get(context.Background(), name, metav1.GetOptions{})
 	if err != nil {
 		return nil, err
@@ -141,6 +182,54 @@ func (k *KubeHelper) GetDeployment(namespace string, name string) (*v1.Deploymen
 	return deployment, nil
 }
 
+func (k *KubeHelper) GetStatefulSets(namespace string) ([]v1.StatefulSet, error) {
+	statefulSets, err := k.client.AppsV1().StatefulSets(namespace).List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	return statefulSets.Items, nil
+}
+
+func (k *KubeHelper) GetStatefulSet(namespace string, name string) (*v1.StatefulSet, error) {
+	if k.informerFactory != nil {
+		if statefulSets := k.informerFactory.Apps().V1().StatefulSets(); statefulSets.Informer().HasSynced() {
+			return statefulSets.Lister().StatefulSets(namespace).Get(name)
+		}
+	}
+
+	statefulSet, err := k.client.AppsV1().StatefulSets(namespace).Get(context.Background(), name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	return statefulSet, nil
+}
+
+func (k *KubeHelper) GetDaemonSets(namespace string) ([]v1.DaemonSet, error) {
+	daemonSets, err := k.client.AppsV1().DaemonSets(namespace).List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	return daemonSets.Items, nil
+}
+
+func (k *KubeHelper) GetDaemonSet(namespace string, name string) (*v1.DaemonSet, error) {
+	if k.informerFactory != nil {
+		if daemonSets := k.informerFactory.Apps().V1().DaemonSets(); daemonSets.Informer().HasSynced() {
+			return daemonSets.Lister().DaemonSets(namespace).Get(name)
+		}
+	}
+
+	daemonSet, err := k.client.AppsV1().DaemonSets(namespace).Get(context.Background(), name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	return daemonSet, nil
+}
+
 func (k *KubeHelper) GetPods(namespace string) ([]corev1.Pod, error) {
 	pods, err := k.client.CoreV1().Pods("").List(context.Background(), metav1.ListOptions{})
 	if err != nil {
@@ -151,6 +240,12 @@ func (k *KubeHelper) GetPods(namespace string) ([]corev1.Pod, error) {
 }
 
 func (k *KubeHelper) GetPod(namespace string, name string) (*corev1.Pod, error) {
+	if k.informerFactory != nil {
+		if pods := k.informerFactory.Core().V1().Pods(); pods.Informer().HasSynced() {
+			return pods.Lister().Pods(namespace).Get(name)
+		}
+	}
+
 	pod, err := k.client.CoreV1().Pods(namespace).Get(context.Background(), name, metav1.GetOptions{})
 	if err != nil {
 		return nil, err
@@ -174,6 +269,10 @@ func (k *KubeHelper) GetKustomizations(namespace string) ([]map[string]interface
 
 // Get a specific kustomization
 func (k *KubeHelper) GetKustomization(namespace, name string) (map[string]interface{}, error) {
+	if obj, ok, err := k.getDynamic(kustomizationGVR, namespace, name); ok {
+		return obj, err
+	}
+
 	unstr, err := k.dynamicClient.Resource(kustomizationGVR).Namespace(namespace).Get(context.Background(), name, metav1.GetOptions{})
 	if err != nil {
 		return nil, err
@@ -196,6 +295,10 @@ func (k *KubeHelper) GetPostgresqls(namespace string) ([]map[string]interface{},
 
 // Get a specific PostgreSQL instance
 func (k *KubeHelper) GetPostgresql(namespace, name string) (map[string]interface{}, error) {
+	if obj, ok, err := k.getDynamic(postgresqlGVR, namespace, name); ok {
+		return obj, err
+	}
+
 	unstr, err := k.dynamicClient.Resource(postgresqlGVR).Namespace(namespace).Get(context.Background(), name, metav1.GetOptions{})
 	if err != nil {
 		return nil, err
@@ -203,6 +306,159 @@ func (k *KubeHelper) GetPostgresql(namespace, name string) (map[string]interface
 	return unstr.Object, nil
 }
 
+func (k *KubeHelper) GetCronJobs(namespace string) ([]batchv1.CronJob, error) {
+	cronJobs, err := k.client.BatchV1().CronJobs(namespace).List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return cronJobs.Items, nil
+}
+
+func (k *KubeHelper) GetCronJob(namespace string, name string) (*batchv1.CronJob, error) {
+	if k.informerFactory != nil {
+		if cronJobs := k.informerFactory.Batch().V1().CronJobs(); cronJobs.Informer().HasSynced() {
+			return cronJobs.Lister().CronJobs(namespace).Get(name)
+		}
+	}
+
+	cronJob, err := k.client.BatchV1().CronJobs(namespace).Get(context.Background(), name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return cronJob, nil
+}
+
+// GetOwnedJobs returns the jobs in the namespace owned by the object with
+// the given UID, such as the jobs created by a CronJob.
+func (k *KubeHelper) GetOwnedJobs(namespace string, owner types.UID) ([]*batchv1.Job, error) {
+	var jobs []*batchv1.Job
+	if k.informerFactory != nil && k.informerFactory.Batch().V1().Jobs().Informer().HasSynced() {
+		all, err := k.informerFactory.Batch().V1().Jobs().Lister().Jobs(namespace).List(labels.Everything())
+		if err != nil {
+			return nil, err
+		}
+		jobs = all
+	} else {
+		list, err := k.client.BatchV1().Jobs(namespace).List(context.Background(), metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		for i := range list.Items {
+			jobs = append(jobs, &list.Items[i])
+		}
+	}
+
+	var owned []*batchv1.Job
+	for _, job := range jobs {
+		for _, ref := range job.OwnerReferences {
+			if ref.UID == owner {
+				owned = append(owned, job)
+				break
+			}
+		}
+	}
+	return owned, nil
+}
+
+// Workloads are the objects of a namespace that make up its health.
+type Workloads struct {
+	Deployments  []*v1.Deployment
+	StatefulSets []*v1.StatefulSet
+	DaemonSets   []*v1.DaemonSet
+	Jobs         []*batchv1.Job
+	Pods         []*corev1.Pod
+}
+
+// GetWorkloads lists the workloads of a namespace that match the selector,
+// from the informer caches once they have synced.
+func (k *KubeHelper) GetWorkloads(namespace string, selector labels.Selector) (*Workloads, error) {
+	workloads := &Workloads{}
+	if k.informerFactory != nil && k.InformersSynced() {
+		var err error
+		if workloads.Deployments, err = k.informerFactory.Apps().V1().Deployments().Lister().Deployments(namespace).List(selector); err != nil {
+			return nil, err
+		}
+		if workloads.StatefulSets, err = k.informerFactory.Apps().V1().StatefulSets().Lister().StatefulSets(namespace).List(selector); err != nil {
+			return nil, err
+		}
+		if workloads.DaemonSets, err = k.informerFactory.Apps().V1().DaemonSets().Lister().DaemonSets(namespace).List(selector); err != nil {
+			return nil, err
+		}
+		if workloads.Jobs, err = k.informerFactory.Batch().V1().Jobs().Lister().Jobs(namespace).List(selector); err != nil {
+			return nil, err
+		}
+		if workloads.Pods, err = k.informerFactory.Core().V1().Pods().Lister().Pods(namespace).List(selector); err != nil {
+			return nil, err
+		}
+		return workloads, nil
+	}
+
+	ctx := context.Background()
+	opts := metav1.ListOptions{LabelSelector: selector.String()}
+	deployments, err := k.client.AppsV1().Deployments(namespace).List(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	for i := range deployments.Items {
+		workloads.Deployments = append(workloads.Deployments, &deployments.Items[i])
+	}
+	statefulSets, err := k.client.AppsV1().StatefulSets(namespace).List(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	for i := range statefulSets.Items {
+		workloads.StatefulSets = append(workloads.StatefulSets, &statefulSets.Items[i])
+	}
+	daemonSets, err := k.client.AppsV1().DaemonSets(namespace).List(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	for i := range daemonSets.Items {
+		workloads.DaemonSets = append(workloads.DaemonSets, &daemonSets.Items[i])
+	}
+	jobs, err := k.client.BatchV1().Jobs(namespace).List(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	for i := range jobs.Items {
+		workloads.Jobs = append(workloads.Jobs, &jobs.Items[i])
+	}
+	pods, err := k.client.CoreV1().Pods(namespace).List(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	for i := range pods.Items {
+		workloads.Pods = append(workloads.Pods, &pods.Items[i])
+	}
+	return workloads, nil
+}
+
+// GetResources lists the objects of any resource in a given namespace
+func (k *KubeHelper) GetResources(gvr schema.GroupVersionResource, namespace string) ([]map[string]interface{}, error) {
+	unstructuredList, err := k.dynamicClient.Resource(gvr).Namespace(namespace).List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	var results []map[string]interface{}
+	for _, item := range unstructuredList.Items {
+		results = append(results, item.Object)
+	}
+	return results, nil
+}
+
+// GetResource returns a specific object of any resource
+func (k *KubeHelper) GetResource(gvr schema.GroupVersionResource, namespace, name string) (map[string]interface{}, error) {
+	if obj, ok, err := k.getDynamic(gvr, namespace, name); ok {
+		return obj, err
+	}
+
+	unstr, err := k.dynamicClient.Resource(gvr).Namespace(namespace).Get(context.Background(), name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return unstr.Object, nil
+}
+
 func (k *KubeHelper) GetJobs(namespace string) ([]batchv1.Job, error) {
 	jobs, err := k.client.BatchV1().Jobs(namespace).List(context.Background(), metav1.ListOptions{})
 	if err != nil {
@@ -212,6 +468,12 @@ func (k *KubeHelper) GetJobs(namespace string) ([]batchv1.Job, error) {
 }
 
 func (k *KubeHelper) GetJob(namespace string, name string) (*batchv1.Job, error) {
+	if k.informerFactory != nil {
+		if jobs := k.informerFactory.Batch().V1().Jobs(); jobs.Informer().HasSynced() {
+			return jobs.Lister().Jobs(namespace).Get(name)
+		}
+	}
+
 	job, err := k.client.BatchV1().Jobs(namespace).Get(context.Background(), name, metav1.GetOptions{})
 	if err != nil {
 		return nil, err
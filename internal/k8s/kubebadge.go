@@ -15,7 +15,11 @@ import (
 )
 
 func (k *KubeHelper) kubebadge() typev1.KubeBadgeInterface {
-	return k.kubeBadgeClient.KubebadgesV1().KubeBadges(config.KubeBadgeNamespace)
+	return k.kubebadgeIn(config.KubeBadgeNamespace)
+}
+
+func (k *KubeHelper) kubebadgeIn(namespace string) typev1.KubeBadgeInterface {
+	return k.kubeBadgeClient.KubebadgesV1().KubeBadges(namespace)
 }
 
 func (k *KubeHelper) GenerateKubeName(name string) string {
@@ -35,27 +39,36 @@ func (k *KubeHelper) GetBadge(name string) (*v1.KubeBadge, error) {
 	return k.kubebadge().Get(context.Background(), name, metav1.GetOptions{})
 }
 
-func (k *KubeHelper) GetBadgeByLabel(key string, value string) ([]v1.KubeBadge, error) {
-	filter := metav1.ListOptions{
-		LabelSelector: key + "=" + value,
-	}
-	list, err := k.kubebadge().List(context.Background(), filter)
-	if err != nil {
-		return nil, err
-	}
-
-	return list.Items, nil
+func (k *KubeHelper) GetBadgeIn(namespace, name string) (*v1.KubeBadge, error) {
+	return k.kubebadgeIn(namespace).Get(context.Background(), name, metav1.GetOptions{})
 }
 
 func (k *KubeHelper) CreateKubeBadge(spec v1.KubeBadgeSpec) (*v1.KubeBadge, error) {
-	kubeBadgeCR := v1.KubeBadge{
+	return k.CreateKubeBadgeIn(config.KubeBadgeNamespace, spec)
+}
+
+func (k *KubeHelper) CreateKubeBadgeIn(namespace string, spec v1.KubeBadgeSpec) (*v1.KubeBadge, error) {
+	kubeBadgeCR := k.newKubeBadge(namespace, spec)
+	return k.kubebadgeIn(namespace).Create(context.Background(), &kubeBadgeCR, metav1.CreateOptions{})
+}
+
+// CreateOwnedKubeBadge creates a KubeBadge in the namespace of its resource,
+// to be garbage collected together with owner.
+func (k *KubeHelper) CreateOwnedKubeBadge(namespace string, spec v1.KubeBadgeSpec, owner metav1.OwnerReference) (*v1.KubeBadge, error) {
+	kubeBadgeCR := k.newKubeBadge(namespace, spec)
+	kubeBadgeCR.OwnerReferences = []metav1.OwnerReference{owner}
+	return k.kubebadgeIn(namespace).Create(context.Background(), &kubeBadgeCR, metav1.CreateOptions{})
+}
+
+func (k *KubeHelper) newKubeBadge(namespace string, spec v1.KubeBadgeSpec) v1.KubeBadge {
+	return v1.KubeBadge{
 		TypeMeta: metav1.TypeMeta{
 			Kind:       config.KubeBadgeCRDKind,
 			APIVersion: config.KubeBadgeCRDAPIVersion,
 		},
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      k.GenerateKubeName(spec.OriginalURL),
-			Namespace: config.KubeBadgeNamespace,
+			Namespace: namespace,
 			Labels: map[string]string{
 				v1.KubeBadgeLabelType:           spec.Type,
 				v1.KubeBadgeLabelAllowed:        strconv.FormatBool(spec.Allowed),
@@ -66,8 +79,6 @@ func (k *KubeHelper) CreateKubeBadge(spec v1.KubeBadgeSpec) (*v1.KubeBadge, erro
 		},
 		Spec: spec,
 	}
-
-	return k.kubebadge().Create(context.Background(), &kubeBadgeCR, metav1.CreateOptions{})
 }
 
 func (k *KubeHelper) UpdateKubeBadge(kubeBadge *v1.KubeBadge) (*v1.KubeBadge, error) {
@@ -79,17 +90,39 @@ func (k *KubeHelper) UpdateKubeBadge(kubeBadge *v1.KubeBadge) (*v1.KubeBadge, er
 		v1.KubeBadgeLabelOwnerNamespace: kubeBadge.Spec.OwnerNamespace,
 	}
 
-	return k.kubebadge().Update(context.Background(), kubeBadge, metav1.UpdateOptions{})
+	return k.kubebadgeIn(kubeBadge.Namespace).Update(context.Background(), kubeBadge, metav1.UpdateOptions{})
+}
+
+// UpdateKubeBadgeStatus writes the status subresource of a KubeBadge. The
+// spec and labels are left untouched.
+func (k *KubeHelper) UpdateKubeBadgeStatus(kubeBadge *v1.KubeBadge) (*v1.KubeBadge, error) {
+	return k.kubebadgeIn(kubeBadge.Namespace).UpdateStatus(context.Background(), kubeBadge, metav1.UpdateOptions{})
 }
 
 func (k *KubeHelper) DeleteKubeBadge(name string) error {
 	return k.kubebadge().Delete(context.Background(), name, metav1.DeleteOptions{})
 }
 
+func (k *KubeHelper) DeleteKubeBadgeIn(namespace, name string) error {
+	return k.DeleteKubeBadgeAt(namespace, name, "")
+}
+
+// DeleteKubeBadgeAt deletes a KubeBadge only if it is still at
+// resourceVersion. An empty resourceVersion deletes any version.
+func (k *KubeHelper) DeleteKubeBadgeAt(namespace, name, resourceVersion string) error {
+	options := metav1.DeleteOptions{}
+	if len(resourceVersion) > 0 {
+		options.Preconditions = &metav1.Preconditions{ResourceVersion: &resourceVersion}
+	}
+	return k.kubebadgeIn(namespace).Delete(context.Background(), name, options)
+}
+
+// NewKubeBadgeInformer watches KubeBadges in all namespaces, so that teams
+// can manage the badges of their own namespaces.
 func (k *KubeHelper) NewKubeBadgeInformer() cache.SharedIndexInformer {
 	return informers.NewKubeBadgeInformer(
 		k.kubeBadgeClient,
-		config.KubeBadgeNamespace,
+		metav1.NamespaceAll,
 		24*time.Hour,
 		cache.Indexers{},
 	)
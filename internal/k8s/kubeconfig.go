@@ -36,6 +36,31 @@ func (k *KubeHelper) DeleteConfig(configMap *v1.ConfigMap) error {
 	return k.client.CoreV1().ConfigMaps(config.KubeBadgeNamespace).Delete(context.Background(), configMap.Name, metav1.DeleteOptions{})
 }
 
+// GetOrCreateHistory returns the ConfigMap holding the badge status history.
+func (k *KubeHelper) GetOrCreateHistory() (*v1.ConfigMap, error) {
+	configMap, err := k.client.CoreV1().ConfigMaps(config.KubeBadgeNamespace).Get(context.Background(), config.KubeBadgeHistoryName, metav1.GetOptions{})
+	if err == nil {
+		return k.initConfigMapData(configMap), nil
+	}
+
+	configMap = &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: config.KubeBadgeHistoryName,
+		},
+		Data: map[string]string{},
+	}
+	configMap, err = k.client.CoreV1().ConfigMaps(config.KubeBadgeNamespace).Create(context.Background(), configMap, metav1.CreateOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	return k.initConfigMapData(configMap), nil
+}
+
+func (k *KubeHelper) UpdateHistory(configMap *v1.ConfigMap) (*v1.ConfigMap, error) {
+	return k.client.CoreV1().ConfigMaps(config.KubeBadgeNamespace).Update(context.Background(), configMap, metav1.UpdateOptions{})
+}
+
 func (k *KubeHelper) createConfigMap() *v1.ConfigMap {
 	return &v1.ConfigMap{
 		ObjectMeta: metav1.ObjectMeta{
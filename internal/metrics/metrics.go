@@ -0,0 +1,211 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kubebadges/kubebadges/internal/cache"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "kubebadges"
+
+// recordedKey marks a request as counted. An alias request is dispatched a
+// second time for the badge it points to, and only that dispatch counts.
+const recordedKey = "metrics.recorded"
+
+var (
+	// Registry holds every kubebadges metric along with the Go runtime and
+	// process collectors.
+	Registry = prometheus.NewRegistry()
+
+	requestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "badge_requests_total",
+		Help:      "Badge requests by engine, badge kind and HTTP status code.",
+	}, []string{"engine", "kind", "code"})
+
+	requestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "badge_request_duration_seconds",
+		Help:      "Time spent serving badge requests.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"engine", "kind"})
+
+	upstreamDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "upstream_request_duration_seconds",
+		Help:      "Latency of requests to the shields.io backend and the Kubernetes API.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"upstream", "method", "code"})
+
+	historyFlushFailures = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "history_flush_failures_total",
+		Help:      "Failed writes of the badge history to the kubebadge-history ConfigMap.",
+	})
+
+	historyBytes = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "history_bytes",
+		Help:      "Encoded size of the badge history as last written.",
+	})
+
+	caches = &cacheCollector{caches: map[string]func() cache.Stats{}}
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		requestsTotal,
+		requestDuration,
+		upstreamDuration,
+		historyFlushFailures,
+		historyBytes,
+		caches,
+	)
+}
+
+// Handler serves the metrics in the Prometheus exposition format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
+
+// Middleware counts the badge requests served by an engine. Other requests,
+// such as the dashboard API, are not recorded.
+func Middleware(engine string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		path := c.Request.URL.Path
+		if !strings.HasPrefix(path, "/badges/") && !strings.HasPrefix(path, "/b/") {
+			c.Next()
+			return
+		}
+
+		start := time.Now()
+		c.Next()
+		if c.GetBool(recordedKey) {
+			return
+		}
+		c.Set(recordedKey, true)
+
+		kind := badgeKind(c.FullPath())
+		requestsTotal.WithLabelValues(engine, kind, strconv.Itoa(c.Writer.Status())).Inc()
+		requestDuration.WithLabelValues(engine, kind).Observe(time.Since(start).Seconds())
+	}
+}
+
+// badgeKind derives the badge kind from the route, which keeps the label
+// cardinality bounded.
+func badgeKind(route string) string {
+	switch {
+	case strings.HasPrefix(route, "/badges/kube/"):
+		kind, _, _ := strings.Cut(strings.TrimPrefix(route, "/badges/kube/"), "/")
+		return kind
+	case strings.HasPrefix(route, "/badges/probe/"):
+		return "probe"
+	case strings.HasPrefix(route, "/badges/group/"):
+		return "group"
+	case strings.HasPrefix(route, "/b/"):
+		return "alias"
+	default:
+		return "unknown"
+	}
+}
+
+// InstrumentRoundTripper records the latency of the requests sent to an
+// upstream. Watch requests stay open for minutes and are not recorded.
+func InstrumentRoundTripper(upstream string, next http.RoundTripper) http.RoundTripper {
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		if req.URL.Query().Get("watch") == "true" {
+			return next.RoundTrip(req)
+		}
+
+		start := time.Now()
+		resp, err := next.RoundTrip(req)
+		code := "error"
+		if err == nil {
+			code = strconv.Itoa(resp.StatusCode)
+		}
+		upstreamDuration.WithLabelValues(upstream, req.Method, code).Observe(time.Since(start).Seconds())
+		return resp, err
+	})
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// ObserveHistoryFlush records a write of the badge history of the given
+// encoded size.
+func ObserveHistoryFlush(size int, err error) {
+	if err != nil {
+		historyFlushFailures.Inc()
+		return
+	}
+	historyBytes.Set(float64(size))
+}
+
+// RegisterInformer exposes whether an informer has completed its initial
+// list as kubebadges_informer_synced.
+func RegisterInformer(name string, hasSynced func() bool) {
+	Registry.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace:   namespace,
+		Name:        "informer_synced",
+		Help:        "Whether the informer has completed its initial list.",
+		ConstLabels: prometheus.Labels{"informer": name},
+	}, func() float64 {
+		if hasSynced() {
+			return 1
+		}
+		return 0
+	}))
+}
+
+// RegisterCache exposes the counters of a cache under the given name. A
+// later registration with the same name replaces the earlier one.
+func RegisterCache(name string, stats func() cache.Stats) {
+	caches.mu.Lock()
+	defer caches.mu.Unlock()
+	caches.caches[name] = stats
+}
+
+var (
+	cacheHitsDesc      = prometheus.NewDesc(namespace+"_cache_hits_total", "Cache lookups that found a live entry.", []string{"cache"}, nil)
+	cacheMissesDesc    = prometheus.NewDesc(namespace+"_cache_misses_total", "Cache lookups that found no live entry.", []string{"cache"}, nil)
+	cacheEvictionsDesc = prometheus.NewDesc(namespace+"_cache_evictions_total", "Expired cache entries removed.", []string{"cache"}, nil)
+	cacheEntriesDesc   = prometheus.NewDesc(namespace+"_cache_entries", "Entries currently held by the cache.", []string{"cache"}, nil)
+)
+
+// cacheCollector reads the counters kept by internal/cache at scrape time.
+type cacheCollector struct {
+	mu     sync.Mutex
+	caches map[string]func() cache.Stats
+}
+
+func (c *cacheCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- cacheHitsDesc
+	ch <- cacheMissesDesc
+	ch <- cacheEvictionsDesc
+	ch <- cacheEntriesDesc
+}
+
+func (c *cacheCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for name, statsFunc := range c.caches {
+		stats := statsFunc()
+		ch <- prometheus.MustNewConstMetric(cacheHitsDesc, prometheus.CounterValue, float64(stats.Hits), name)
+		ch <- prometheus.MustNewConstMetric(cacheMissesDesc, prometheus.CounterValue, float64(stats.Misses), name)
+		ch <- prometheus.MustNewConstMetric(cacheEvictionsDesc, prometheus.CounterValue, float64(stats.Evictions), name)
+		ch <- prometheus.MustNewConstMetric(cacheEntriesDesc, prometheus.GaugeValue, float64(stats.Size), name)
+	}
+}
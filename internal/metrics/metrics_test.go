@@ -0,0 +1,93 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kubebadges/kubebadges/internal/cache"
+	"github.com/kubebadges/kubebadges/internal/model"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestBadgeKind(t *testing.T) {
+	testCases := []struct {
+		route    string
+		expected string
+	}{
+		{route: "/badges/kube/deployment/:namespace/:deployment", expected: "deployment"},
+		{route: "/badges/kube/node/:node", expected: "node"},
+		{route: "/badges/probe/:name", expected: "probe"},
+		{route: "/badges/group/:name", expected: "group"},
+		{route: "/b/*alias", expected: "alias"},
+		{route: "", expected: "unknown"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.expected, func(t *testing.T) {
+			if actual := badgeKind(tc.route); actual != tc.expected {
+				t.Errorf("Expected %q, but got %q", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestMiddlewareCountsAliasOnce(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.Use(Middleware("test"))
+	engine.GET("/badges/kube/pod/:namespace/:pod", func(c *gin.Context) {
+		c.String(http.StatusOK, "ok")
+	})
+	engine.GET("/b/*alias", func(c *gin.Context) {
+		c.Request.URL.Path = "/badges/kube/pod/default/web"
+		engine.HandleContext(c)
+	})
+	engine.GET("/api/nodes", func(c *gin.Context) {
+		c.String(http.StatusOK, "ok")
+	})
+
+	for _, path := range []string{"/b/web", "/api/nodes"} {
+		engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	if count := testutil.ToFloat64(requestsTotal.WithLabelValues("test", "pod", "200")); count != 1 {
+		t.Errorf("Expected 1 pod request, but got %v", count)
+	}
+	if count := testutil.ToFloat64(requestsTotal.WithLabelValues("test", "alias", "200")); count != 0 {
+		t.Errorf("Expected no alias request, but got %v", count)
+	}
+}
+
+func TestCacheCollector(t *testing.T) {
+	c := cache.NewCache[string, int]()
+	c.Set("foo", 1, 0)
+	c.Get("bar")
+	RegisterCache("test", c.Stats)
+
+	if count := testutil.CollectAndCount(caches, "kubebadges_cache_misses_total"); count < 1 {
+		t.Errorf("Expected cache metrics, but got %d", count)
+	}
+}
+
+func TestStatusCollector(t *testing.T) {
+	collector := &statusCollector{statuses: func() []model.BadgeStatus {
+		return []model.BadgeStatus{
+			{Kind: "deployment", Namespace: "shop", Name: "api", Message: "1/3 Available", Color: "yellow"},
+			{Kind: "deployment", Namespace: "shop", Name: "api", Message: "1/3 Available", Color: "yellow"},
+			{Kind: "job", Namespace: "shop", Name: "migrate", Message: "Failed", Color: "red"},
+		}
+	}}
+
+	expected := `
+# HELP kubebadges_badge_status Computed state of each allowed KubeBadge, always 1. The message and color labels carry the state.
+# TYPE kubebadges_badge_status gauge
+kubebadges_badge_status{color="red",kind="job",message="Failed",name="migrate",namespace="shop"} 1
+kubebadges_badge_status{color="yellow",kind="deployment",message="1/3 Available",name="api",namespace="shop"} 1
+`
+	if err := testutil.CollectAndCompare(collector, strings.NewReader(expected)); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,47 @@
+package metrics
+
+import (
+	"sync"
+
+	"github.com/kubebadges/kubebadges/internal/model"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var badgeStatusDesc = prometheus.NewDesc(
+	namespace+"_badge_status",
+	"Computed state of each allowed KubeBadge, always 1. The message and color labels carry the state.",
+	[]string{"kind", "namespace", "name", "message", "color"}, nil,
+)
+
+var statusCollectorOnce sync.Once
+
+// RegisterBadgeStatus exposes the badge states returned by statuses as
+// kubebadges_badge_status. The states are computed on every scrape, so a
+// badge that changes color replaces its previous series.
+func RegisterBadgeStatus(statuses func() []model.BadgeStatus) {
+	statusCollectorOnce.Do(func() {
+		Registry.MustRegister(&statusCollector{statuses: statuses})
+	})
+}
+
+type statusCollector struct {
+	statuses func() []model.BadgeStatus
+}
+
+func (c *statusCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- badgeStatusDesc
+}
+
+func (c *statusCollector) Collect(ch chan<- prometheus.Metric) {
+	seen := map[[3]string]bool{}
+	for _, status := range c.statuses() {
+		// two KubeBadges may point at the same resource
+		id := [3]string{status.Kind, status.Namespace, status.Name}
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		ch <- prometheus.MustNewConstMetric(badgeStatusDesc, prometheus.GaugeValue, 1,
+			status.Kind, status.Namespace, status.Name, status.Message, status.Color)
+	}
+}
@@ -1,5 +1,11 @@
 package model
 
+import (
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
 type KubeBadges struct {
 	Kind  string `json:"kind"`
 	Name  string `json:"name"`
@@ -14,3 +20,58 @@ type KubeBadges struct {
 type KubeBadgesConfig struct {
 	BadgeBaseURL string `json:"badge_base_url"`
 }
+
+// BadgeStatus is the computed state of a KubeBadge, as shown in its image. Kind, Namespace and Name are parsed from Key.
+type BadgeStatus struct {
+	Key       string `json:"key"`
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+	Label     string `json:"label"`
+	Message   string `json:"message"`
+	Color     string `json:"color"`
+	Group     string `json:"group,omitempty"` // status page section
+	Allowed   bool   `json:"allowed"`
+	Notify    bool   `json:"notify,omitempty"`
+	Error     string `json:"error,omitempty"` // why the badge could not be evaluated
+}
+
+// StatusChange is an entry in the status history of a badge. An entry is
+// recorded when the badge color changes.
+type StatusChange struct {
+	Time    time.Time `json:"time"`
+	Color   string    `json:"color"`
+	Message string    `json:"message"`
+}
+
+// OrphanedBadge is a KubeBadge whose resource no longer exists. DeleteAt is
+// set when orphans are deleted after a grace period.
+type OrphanedBadge struct {
+	Key           string     `json:"key"`
+	Namespace     string     `json:"namespace"`
+	Name          string     `json:"name"`
+	Allowed       bool       `json:"allowed"`
+	OrphanedSince time.Time  `json:"orphaned_since"`
+	DeleteAt      *time.Time `json:"delete_at,omitempty"`
+}
+
+// CustomResourceBadge maps a custom resource to a badge. The state is read
+// either from the status condition ConditionType or from the JSONPath
+// expression, and Colors maps that state to a badge color.
+type CustomResourceBadge struct {
+	Group         string            `json:"group"`
+	Version       string            `json:"version"`
+	Resource      string            `json:"resource"`
+	ConditionType string            `json:"condition_type,omitempty"`
+	JSONPath      string            `json:"json_path,omitempty"`
+	Colors        map[string]string `json:"colors,omitempty"`
+	DefaultColor  string            `json:"default_color,omitempty"`
+}
+
+func (c CustomResourceBadge) GVR() schema.GroupVersionResource {
+	return schema.GroupVersionResource{
+		Group:    c.Group,
+		Version:  c.Version,
+		Resource: c.Resource,
+	}
+}
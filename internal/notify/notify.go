@@ -0,0 +1,241 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kubebadges/kubebadges/internal/badges"
+	"github.com/kubebadges/kubebadges/internal/config"
+	"github.com/kubebadges/kubebadges/internal/model"
+)
+
+const sendTimeout = 10 * time.Second
+
+// Event describes a badge whose color changed.
+type Event struct {
+	Key             string    `json:"key"`
+	Label           string    `json:"label"`
+	Message         string    `json:"message"`
+	Color           string    `json:"color"`
+	PreviousMessage string    `json:"previous_message"`
+	PreviousColor   string    `json:"previous_color"`
+	Time            time.Time `json:"time"`
+}
+
+func (e Event) text() string {
+	return fmt.Sprintf("%s changed from %s to %s", e.Label, e.PreviousMessage, e.Message)
+}
+
+// Sender delivers an event to one destination.
+type Sender interface {
+	Send(ctx context.Context, event Event) error
+}
+
+// badgeState is the last reported state of a badge, and the color it is
+// changing to while the change is being debounced.
+type badgeState struct {
+	color   string
+	message string
+
+	pendingColor string
+	pendingSince time.Time
+}
+
+// Notifier periodically evaluates the badges that opted in and sends an
+// event when a badge keeps a new color for longer than the debounce period.
+// The first evaluation of a badge only records its state.
+type Notifier struct {
+	statuses func() []model.BadgeStatus
+	senders  []Sender
+	interval time.Duration
+	debounce time.Duration
+
+	mu     sync.Mutex
+	states map[string]*badgeState // key is the badge key
+}
+
+func NewNotifier(cfg *config.Config, statuses func() []model.BadgeStatus) *Notifier {
+	client := &http.Client{Timeout: sendTimeout}
+	var senders []Sender
+	for _, url := range cfg.NotifyWebhookURLs {
+		senders = append(senders, &WebhookSender{URL: url, Client: client})
+	}
+	for _, url := range cfg.NotifySlackURLs {
+		senders = append(senders, &SlackSender{URL: url, Client: client})
+	}
+	for _, url := range cfg.NotifyTeamsURLs {
+		senders = append(senders, &TeamsSender{URL: url, Client: client})
+	}
+
+	return &Notifier{
+		statuses: statuses,
+		senders:  senders,
+		interval: time.Duration(cfg.NotifyInterval) * time.Second,
+		debounce: time.Duration(cfg.NotifyDebounce) * time.Second,
+		states:   map[string]*badgeState{},
+	}
+}
+
+// Enabled reports whether any destination is configured.
+func (n *Notifier) Enabled() bool {
+	return len(n.senders) > 0 && n.interval > 0
+}
+
+func (n *Notifier) Run() {
+	ticker := time.NewTicker(n.interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		for _, event := range n.Observe(n.statuses(), time.Now()) {
+			n.send(event)
+		}
+	}
+}
+
+// Observe records the current statuses and returns the events to send.
+// Badges that are no longer listed or opted in are forgotten.
+func (n *Notifier) Observe(statuses []model.BadgeStatus, now time.Time) []Event {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	var events []Event
+	seen := map[string]bool{}
+	for _, status := range statuses {
+		if !status.Notify {
+			continue
+		}
+		seen[status.Key] = true
+
+		state, ok := n.states[status.Key]
+		if !ok {
+			n.states[status.Key] = &badgeState{color: status.Color, message: status.Message}
+			continue
+		}
+		if status.Color == state.color {
+			state.message = status.Message
+			state.pendingColor = ""
+			continue
+		}
+		if status.Color != state.pendingColor {
+			state.pendingColor = status.Color
+			state.pendingSince = now
+		}
+		if now.Sub(state.pendingSince) < n.debounce {
+			continue
+		}
+
+		events = append(events, Event{
+			Key:             status.Key,
+			Label:           status.Label,
+			Message:         status.Message,
+			Color:           status.Color,
+			PreviousMessage: state.message,
+			PreviousColor:   state.color,
+			Time:            now,
+		})
+		state.color = status.Color
+		state.message = status.Message
+		state.pendingColor = ""
+	}
+
+	for key := range n.states {
+		if !seen[key] {
+			delete(n.states, key)
+		}
+	}
+	return events
+}
+
+func (n *Notifier) send(event Event) {
+	for _, sender := range n.senders {
+		ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+		if err := sender.Send(ctx, event); err != nil {
+			slog.Error("failed to send notification", "key", event.Key, "error", err)
+		}
+		cancel()
+	}
+}
+
+// WebhookSender posts the event as JSON.
+type WebhookSender struct {
+	URL    string
+	Client *http.Client
+}
+
+func (w *WebhookSender) Send(ctx context.Context, event Event) error {
+	return postJSON(ctx, w.Client, w.URL, event)
+}
+
+// SlackSender posts to a Slack-compatible incoming webhook.
+type SlackSender struct {
+	URL    string
+	Client *http.Client
+}
+
+func (s *SlackSender) Send(ctx context.Context, event Event) error {
+	return postJSON(ctx, s.Client, s.URL, map[string]interface{}{
+		"text": event.text(),
+		"attachments": []map[string]string{{
+			"color":    hexColor(event.Color),
+			"fallback": event.text(),
+			"text":     fmt.Sprintf("*%s*: %s", event.Label, event.Message),
+			"footer":   event.Key,
+		}},
+	})
+}
+
+// TeamsSender posts a MessageCard to a Microsoft Teams incoming webhook.
+type TeamsSender struct {
+	URL    string
+	Client *http.Client
+}
+
+func (t *TeamsSender) Send(ctx context.Context, event Event) error {
+	return postJSON(ctx, t.Client, t.URL, map[string]interface{}{
+		"@type":      "MessageCard",
+		"@context":   "https://schema.org/extensions",
+		"summary":    event.text(),
+		"themeColor": strings.TrimPrefix(hexColor(event.Color), "#"),
+		"title":      event.Label,
+		"text":       fmt.Sprintf("%s → **%s**<br>%s", event.PreviousMessage, event.Message, event.Key),
+	})
+}
+
+// hexColor resolves a badge color name, which chat tools do not understand.
+func hexColor(color string) string {
+	hex := badges.ResolveColor(color, "#9f9f9f")
+	if len(hex) == 4 {
+		hex = string([]byte{'#', hex[1], hex[1], hex[2], hex[2], hex[3], hex[3]})
+	}
+	return hex
+}
+
+func postJSON(ctx context.Context, client *http.Client, url string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kubebadges/kubebadges/internal/config"
+	"github.com/kubebadges/kubebadges/internal/model"
+)
+
+func status(color, message string) []model.BadgeStatus {
+	return []model.BadgeStatus{{Key: "/kube/deployment/shop/api", Label: "api", Message: message, Color: color, Notify: true}}
+}
+
+func TestObserveDebounce(t *testing.T) {
+	notifier := NewNotifier(&config.Config{NotifyInterval: 30, NotifyDebounce: 60}, nil)
+	start := time.Now()
+
+	steps := []struct {
+		name       string
+		offset     time.Duration
+		color      string
+		wantEvents int
+	}{
+		{"initial state", 0, "green", 0},
+		{"change starts", 30 * time.Second, "red", 0},
+		{"flaps back", 60 * time.Second, "green", 0},
+		{"change again", 90 * time.Second, "red", 0},
+		{"still debouncing", 120 * time.Second, "red", 0},
+		{"debounced", 150 * time.Second, "red", 1},
+		{"no repeat", 180 * time.Second, "red", 0},
+	}
+	for _, step := range steps {
+		events := notifier.Observe(status(step.color, step.color), start.Add(step.offset))
+		if len(events) != step.wantEvents {
+			t.Fatalf("%s: got %d events, want %d", step.name, len(events), step.wantEvents)
+		}
+		if len(events) == 1 && (events[0].PreviousColor != "green" || events[0].Color != "red") {
+			t.Errorf("%s: unexpected event %+v", step.name, events[0])
+		}
+	}
+}
+
+func TestObserveOptIn(t *testing.T) {
+	notifier := NewNotifier(&config.Config{}, nil)
+	statuses := status("green", "Ready")
+	statuses[0].Notify = false
+
+	notifier.Observe(statuses, time.Now())
+	if len(notifier.states) != 0 {
+		t.Errorf("expected badges without notify to be ignored, got %d states", len(notifier.states))
+	}
+}
+
+func TestSenders(t *testing.T) {
+	var payload map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		payload = nil
+		_ = json.Unmarshal(body, &payload)
+	}))
+	defer server.Close()
+
+	event := Event{Key: "/kube/job/shop/migrate", Label: "migrate", Message: "Failed", Color: "red", PreviousMessage: "Succeeded", PreviousColor: "green"}
+	tests := []struct {
+		name   string
+		sender Sender
+		field  string
+		want   interface{}
+	}{
+		{"webhook", &WebhookSender{URL: server.URL, Client: server.Client()}, "previous_message", "Succeeded"},
+		{"slack", &SlackSender{URL: server.URL, Client: server.Client()}, "text", "migrate changed from Succeeded to Failed"},
+		{"teams", &TeamsSender{URL: server.URL, Client: server.Client()}, "themeColor", "e05d44"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.sender.Send(context.Background(), event); err != nil {
+				t.Fatal(err)
+			}
+			if payload[tt.field] != tt.want {
+				t.Errorf("%s = %v, want %v", tt.field, payload[tt.field], tt.want)
+			}
+		})
+	}
+}
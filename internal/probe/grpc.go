@@ -0,0 +1,113 @@
+package probe
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+
+	v1 "github.com/kubebadges/kubebadges/pkg/apis/kubebadges/v1"
+	"golang.org/x/net/http2"
+)
+
+// grpcServing is the SERVING value of grpc.health.v1.HealthCheckResponse.
+const grpcServing = 1
+
+// h2cTransport speaks cleartext HTTP/2, which is what in-cluster gRPC
+// servers expose.
+var h2cTransport = &http2.Transport{
+	AllowHTTP: true,
+	DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+		var dialer net.Dialer
+		return dialer.DialContext(ctx, network, addr)
+	},
+}
+
+// probeGRPCHealth calls grpc.health.v1.Health/Check for the overall server
+// health. The request and response messages are small enough to be encoded
+// by hand, which avoids depending on the full gRPC stack.
+func probeGRPCHealth(ctx context.Context, custom v1.Custom) error {
+	address, err := hostPort(custom)
+	if err != nil {
+		return err
+	}
+
+	// a length-prefixed message holding an empty HealthCheckRequest
+	body := []byte{0, 0, 0, 0, 0}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "http://"+address+"/grpc.health.v1.Health/Check", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/grpc")
+	req.Header.Set("TE", "trailers")
+
+	resp, err := h2cTransport.RoundTrip(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	payload, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	status := resp.Trailer.Get("Grpc-Status")
+	if status == "" {
+		status = resp.Header.Get("Grpc-Status")
+	}
+	if status != "0" {
+		return fmt.Errorf("grpc status %s: %s", status, resp.Trailer.Get("Grpc-Message"))
+	}
+
+	servingStatus, err := parseHealthCheckResponse(payload)
+	if err != nil {
+		return err
+	}
+	if servingStatus != grpcServing {
+		return fmt.Errorf("serving status %d", servingStatus)
+	}
+	return nil
+}
+
+// parseHealthCheckResponse extracts the status field (number 1, varint) from
+// a length-prefixed HealthCheckResponse message.
+func parseHealthCheckResponse(payload []byte) (uint64, error) {
+	if len(payload) < 5 {
+		return 0, errors.New("short grpc response")
+	}
+	if payload[0] != 0 {
+		return 0, errors.New("compressed grpc response")
+	}
+	length := binary.BigEndian.Uint32(payload[1:5])
+	message := payload[5:]
+	if uint32(len(message)) < length {
+		return 0, errors.New("truncated grpc response")
+	}
+	message = message[:length]
+
+	for len(message) > 0 {
+		tag, n := binary.Uvarint(message)
+		if n <= 0 {
+			return 0, errors.New("invalid grpc response")
+		}
+		message = message[n:]
+		if tag&0x7 != 0 {
+			return 0, errors.New("unexpected field in grpc response")
+		}
+		value, n := binary.Uvarint(message)
+		if n <= 0 {
+			return 0, errors.New("invalid grpc response")
+		}
+		message = message[n:]
+		if tag>>3 == 1 {
+			return value, nil
+		}
+	}
+	// an empty message carries the default status, UNKNOWN
+	return 0, nil
+}
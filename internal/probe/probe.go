@@ -0,0 +1,152 @@
+package probe
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	v1 "github.com/kubebadges/kubebadges/pkg/apis/kubebadges/v1"
+)
+
+const (
+	TypeHTTP       = "http"
+	TypeTCP        = "tcp"
+	TypeGRPCHealth = "grpc-health"
+
+	DefaultInterval = 60 * time.Second
+	DefaultTimeout  = 5 * time.Second
+	MinInterval     = 5 * time.Second
+)
+
+// Result is the outcome of a single probe.
+type Result struct {
+	Up        bool
+	Latency   time.Duration
+	CheckedAt time.Time
+	Error     string
+}
+
+// Supported reports whether the probe type is implemented.
+func Supported(probeType string) bool {
+	switch probeType {
+	case TypeHTTP, TypeTCP, TypeGRPCHealth:
+		return true
+	}
+	return false
+}
+
+// Interval returns the probe interval of the spec, applying the default and
+// the lower bound.
+func Interval(custom v1.Custom) time.Duration {
+	if custom.IntervalSeconds <= 0 {
+		return DefaultInterval
+	}
+	interval := time.Duration(custom.IntervalSeconds) * time.Second
+	if interval < MinInterval {
+		return MinInterval
+	}
+	return interval
+}
+
+// Timeout returns the probe timeout of the spec, applying the default.
+func Timeout(custom v1.Custom) time.Duration {
+	if custom.TimeoutSeconds <= 0 {
+		return DefaultTimeout
+	}
+	return time.Duration(custom.TimeoutSeconds) * time.Second
+}
+
+// Run probes the target described by the spec and measures its latency.
+func Run(ctx context.Context, custom v1.Custom) Result {
+	ctx, cancel := context.WithTimeout(ctx, Timeout(custom))
+	defer cancel()
+
+	start := time.Now()
+	var err error
+	switch custom.Type {
+	case TypeHTTP:
+		err = probeHTTP(ctx, custom)
+	case TypeTCP:
+		err = probeTCP(ctx, custom)
+	case TypeGRPCHealth:
+		err = probeGRPCHealth(ctx, custom)
+	default:
+		err = fmt.Errorf("unsupported probe type %q", custom.Type)
+	}
+
+	result := Result{
+		Up:        err == nil,
+		Latency:   time.Since(start),
+		CheckedAt: time.Now(),
+	}
+	if err != nil {
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// hostPort joins the address and port. An address that already carries a
+// port is used as-is.
+func hostPort(custom v1.Custom) (string, error) {
+	if len(custom.Address) == 0 {
+		return "", errors.New("address is required")
+	}
+	if _, _, err := net.SplitHostPort(custom.Address); err == nil {
+		return custom.Address, nil
+	}
+	if custom.Port <= 0 {
+		return "", errors.New("port is required")
+	}
+	return net.JoinHostPort(custom.Address, strconv.Itoa(custom.Port)), nil
+}
+
+// probeHTTP issues a GET and treats any 2xx or 3xx response as up. The
+// address may be a full URL, otherwise http://address:port/ is requested.
+func probeHTTP(ctx context.Context, custom v1.Custom) error {
+	target := custom.Address
+	if !strings.Contains(target, "://") {
+		address, err := hostPort(custom)
+		if err != nil {
+			return err
+		}
+		target = "http://" + address + "/"
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return err
+	}
+	client := http.Client{
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 400 {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func probeTCP(ctx context.Context, custom v1.Custom) error {
+	address, err := hostPort(custom)
+	if err != nil {
+		return err
+	}
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
@@ -0,0 +1,122 @@
+package probe
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	v1 "github.com/kubebadges/kubebadges/pkg/apis/kubebadges/v1"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+func TestRunHTTP(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/healthz" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	if result := Run(context.Background(), v1.Custom{Type: TypeHTTP, Address: server.URL + "/healthz"}); !result.Up {
+		t.Errorf("expected up, got error %q", result.Error)
+	}
+	if result := Run(context.Background(), v1.Custom{Type: TypeHTTP, Address: server.URL + "/down"}); result.Up {
+		t.Error("expected down")
+	}
+}
+
+func TestRunTCP(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	host, port, _ := net.SplitHostPort(listener.Addr().String())
+	portNumber, _ := strconv.Atoi(port)
+
+	custom := v1.Custom{Type: TypeTCP, Address: host, Port: portNumber}
+	if result := Run(context.Background(), custom); !result.Up {
+		t.Errorf("expected up, got error %q", result.Error)
+	}
+
+	listener.Close()
+	if result := Run(context.Background(), custom); result.Up {
+		t.Error("expected down after the listener is closed")
+	}
+}
+
+func TestRunGRPCHealth(t *testing.T) {
+	servingStatus := byte(1)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/grpc.health.v1.Health/Check" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/grpc")
+		w.Header().Set("Trailer", "Grpc-Status")
+		_, _ = w.Write([]byte{0, 0, 0, 0, 2, 0x08, servingStatus})
+		w.Header().Set("Grpc-Status", "0")
+	})
+	server := httptest.NewServer(h2c.NewHandler(handler, &http2.Server{}))
+	defer server.Close()
+
+	custom := v1.Custom{Type: TypeGRPCHealth, Address: server.Listener.Addr().String()}
+	if result := Run(context.Background(), custom); !result.Up {
+		t.Errorf("expected up, got error %q", result.Error)
+	}
+
+	servingStatus = 2
+	if result := Run(context.Background(), custom); result.Up {
+		t.Error("expected down when not serving")
+	}
+}
+
+func TestRunUnsupported(t *testing.T) {
+	if result := Run(context.Background(), v1.Custom{Type: "icmp"}); result.Up || result.Error == "" {
+		t.Errorf("expected an error for an unsupported probe, got %+v", result)
+	}
+}
+
+func TestIntervalAndTimeout(t *testing.T) {
+	if got := Interval(v1.Custom{}); got != DefaultInterval {
+		t.Errorf("Interval() = %v, want %v", got, DefaultInterval)
+	}
+	if got := Interval(v1.Custom{IntervalSeconds: 1}); got != MinInterval {
+		t.Errorf("Interval() = %v, want %v", got, MinInterval)
+	}
+	if got := Timeout(v1.Custom{TimeoutSeconds: 2}); got != 2*time.Second {
+		t.Errorf("Timeout() = %v, want %v", got, 2*time.Second)
+	}
+}
+
+func TestParseHealthCheckResponse(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload []byte
+		want    uint64
+		wantErr bool
+	}{
+		{name: "serving", payload: []byte{0, 0, 0, 0, 2, 0x08, 0x01}, want: 1},
+		{name: "not serving", payload: []byte{0, 0, 0, 0, 2, 0x08, 0x02}, want: 2},
+		{name: "empty message", payload: []byte{0, 0, 0, 0, 0}, want: 0},
+		{name: "short", payload: []byte{0, 0}, wantErr: true},
+		{name: "truncated", payload: []byte{0, 0, 0, 0, 4, 0x08}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseHealthCheckResponse(tt.payload)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseHealthCheckResponse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseHealthCheckResponse() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
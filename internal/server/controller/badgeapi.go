@@ -0,0 +1,317 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	"github.com/gin-gonic/gin"
+	"github.com/kubebadges/kubebadges/internal/auth"
+	"github.com/kubebadges/kubebadges/internal/config"
+	"github.com/kubebadges/kubebadges/internal/server/middleware"
+	"github.com/kubebadges/kubebadges/internal/service"
+	v1 "github.com/kubebadges/kubebadges/pkg/apis/kubebadges/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// BadgeResource is a KubeBadge as served by the badges API.
+type BadgeResource struct {
+	Namespace       string             `json:"namespace"`
+	Name            string             `json:"name"`
+	ResourceVersion string             `json:"resource_version"`
+	Spec            v1.KubeBadgeSpec   `json:"spec"`
+	Status          v1.KubeBadgeStatus `json:"status"`
+}
+
+func newBadgeResource(kubeBadge *v1.KubeBadge) BadgeResource {
+	return BadgeResource{
+		Namespace:       kubeBadge.Namespace,
+		Name:            kubeBadge.Name,
+		ResourceVersion: kubeBadge.ResourceVersion,
+		Spec:            kubeBadge.Spec,
+		Status:          kubeBadge.Status,
+	}
+}
+
+// BadgeWriteRequest is the body of PUT and PATCH. For PATCH, Spec is a JSON
+// merge patch of the spec.
+type BadgeWriteRequest struct {
+	ResourceVersion string          `json:"resource_version"`
+	Spec            json.RawMessage `json:"spec"`
+}
+
+type CreateBadgeRequest struct {
+	Namespace string           `json:"namespace"`
+	Spec      v1.KubeBadgeSpec `json:"spec"`
+}
+
+// ListBadges returns every KubeBadge the user may list, optionally filtered
+// by the namespace, type, allowed and owner_namespace query parameters.
+func (s *KubeController) ListBadges(c *gin.Context) {
+	var allowed *bool
+	if value, ok := c.GetQuery("allowed"); ok {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "allowed must be true or false"})
+			return
+		}
+		allowed = &parsed
+	}
+
+	kubeBadges, ok := readableBadges(c, s.KubeBadgesService.ListAllKubeBadges())
+	if !ok {
+		return
+	}
+	result := filterBadges(kubeBadges, c.Query("namespace"), c.Query("type"), c.Query("owner_namespace"), allowed)
+	c.JSON(http.StatusOK, result)
+}
+
+// readableBadges leaves out the KubeBadges of the namespaces in which the
+// user of the request may not list KubeBadges. When an access review
+// fails, it aborts the request with 500 and returns false.
+func readableBadges(c *gin.Context, kubeBadges []*v1.KubeBadge) ([]*v1.KubeBadge, bool) {
+	listable := listableIn(c)
+	result := make([]*v1.KubeBadge, 0, len(kubeBadges))
+	for _, kubeBadge := range kubeBadges {
+		allowed, err := listable(kubeBadge.Namespace)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return nil, false
+		}
+		if allowed {
+			result = append(result, kubeBadge)
+		}
+	}
+	return result, true
+}
+
+// listableIn returns a function that reports whether the user of the
+// request may list the KubeBadges of a namespace, reviewing each namespace
+// once.
+func listableIn(c *gin.Context) func(namespace string) (bool, error) {
+	reviewed := map[string]bool{}
+	return func(namespace string) (bool, error) {
+		if allowed, ok := reviewed[namespace]; ok {
+			return allowed, nil
+		}
+		allowed, err := middleware.CanIn(c, namespace, auth.VerbList)
+		if err != nil {
+			return false, err
+		}
+		reviewed[namespace] = allowed
+		return allowed, nil
+	}
+}
+
+func filterBadges(kubeBadges []*v1.KubeBadge, namespace, badgeType, ownerNamespace string, allowed *bool) []BadgeResource {
+	result := []BadgeResource{}
+	for _, kubeBadge := range kubeBadges {
+		switch {
+		case len(namespace) > 0 && kubeBadge.Namespace != namespace,
+			len(badgeType) > 0 && kubeBadge.Spec.Type != badgeType,
+			len(ownerNamespace) > 0 && kubeBadge.Spec.OwnerNamespace != ownerNamespace,
+			allowed != nil && kubeBadge.Spec.Allowed != *allowed:
+			continue
+		}
+		result = append(result, newBadgeResource(kubeBadge))
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Namespace != result[j].Namespace {
+			return result[i].Namespace < result[j].Namespace
+		}
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+// GetBadge returns a KubeBadge by name. KubeBadges outside the kubebadges
+// namespace are addressed with the namespace query parameter.
+func (s *KubeController) GetBadge(c *gin.Context) {
+	namespace := badgeNamespace(c)
+	if !middleware.AuthorizeIn(c, namespace, auth.VerbGet) {
+		return
+	}
+	kubeBadge, err := s.KubeHelper.GetBadgeIn(namespace, c.Param("name"))
+	if err != nil {
+		c.JSON(apiErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, newBadgeResource(kubeBadge))
+}
+
+// CreateBadge creates a KubeBadge named after the key in spec.originalURL.
+// Outside the kubebadges namespace, only badges of resources in the same
+// namespace can be created, as no other would govern its badge.
+func (s *KubeController) CreateBadge(c *gin.Context) {
+	var req CreateBadgeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	parsed, ok := parseBadgeKey(req.Spec.OriginalURL)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "spec.originalURL is not a valid badge key"})
+		return
+	}
+	if len(req.Spec.Type) == 0 {
+		req.Spec.Type = parsed.kind
+	}
+	if len(req.Namespace) == 0 {
+		req.Namespace = config.KubeBadgeNamespace
+	}
+	if !service.NamespaceOwns(req.Namespace, req.Spec) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errForeignBadge(req.Namespace).Error()})
+		return
+	}
+	if !middleware.AuthorizeIn(c, req.Namespace, auth.VerbUpdate) ||
+		req.Spec.Allowed && !middleware.AuthorizeIn(c, req.Namespace, auth.VerbPublish) {
+		return
+	}
+
+	kubeBadge, err := s.KubeHelper.CreateKubeBadgeIn(req.Namespace, req.Spec)
+	if err != nil {
+		c.JSON(apiErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, newBadgeResource(kubeBadge))
+}
+
+// ReplaceBadge replaces the spec of a KubeBadge at the given
+// resource_version.
+func (s *KubeController) ReplaceBadge(c *gin.Context) {
+	s.writeBadge(c, func(current v1.KubeBadgeSpec, raw json.RawMessage) (v1.KubeBadgeSpec, error) {
+		var spec v1.KubeBadgeSpec
+		err := json.Unmarshal(raw, &spec)
+		return spec, err
+	})
+}
+
+// PatchBadge applies a JSON merge patch to the spec of a KubeBadge at the
+// given resource_version.
+func (s *KubeController) PatchBadge(c *gin.Context) {
+	s.writeBadge(c, mergeSpecPatch)
+}
+
+func (s *KubeController) writeBadge(c *gin.Context, apply func(current v1.KubeBadgeSpec, raw json.RawMessage) (v1.KubeBadgeSpec, error)) {
+	var req BadgeWriteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if len(req.ResourceVersion) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "resource_version is required"})
+		return
+	}
+
+	namespace := badgeNamespace(c)
+	if !middleware.AuthorizeIn(c, namespace, auth.VerbUpdate) {
+		return
+	}
+	current, err := s.KubeHelper.GetBadgeIn(namespace, c.Param("name"))
+	if err != nil {
+		c.JSON(apiErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	if metav1.GetControllerOf(current) != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": errManagedBadge.Error()})
+		return
+	}
+	if current.ResourceVersion != req.ResourceVersion {
+		c.JSON(http.StatusConflict, gin.H{"error": "the badge has been modified, get it again and retry"})
+		return
+	}
+	spec, err := apply(current.Spec, req.Spec)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if spec.OriginalURL != current.Spec.OriginalURL {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "spec.originalURL cannot be changed"})
+		return
+	}
+	if !service.NamespaceOwns(namespace, spec) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errForeignBadge(namespace).Error()})
+		return
+	}
+	if spec.Allowed && !current.Spec.Allowed && !middleware.AuthorizeIn(c, namespace, auth.VerbPublish) {
+		return
+	}
+
+	updated := current.DeepCopy()
+	updated.Spec = spec
+	kubeBadge, err := s.KubeHelper.UpdateKubeBadge(updated)
+	if err != nil {
+		c.JSON(apiErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, newBadgeResource(kubeBadge))
+}
+
+// DeleteBadge deletes a KubeBadge, only at the resource_version query
+// parameter when given.
+func (s *KubeController) DeleteBadge(c *gin.Context) {
+	namespace := badgeNamespace(c)
+	if !middleware.AuthorizeIn(c, namespace, auth.VerbDelete) {
+		return
+	}
+	current, err := s.KubeHelper.GetBadgeIn(namespace, c.Param("name"))
+	if err != nil {
+		c.JSON(apiErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	if metav1.GetControllerOf(current) != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": errManagedBadge.Error()})
+		return
+	}
+	resourceVersion := c.DefaultQuery("resource_version", current.ResourceVersion)
+	err = s.KubeHelper.DeleteKubeBadgeAt(namespace, current.Name, resourceVersion)
+	if err != nil {
+		c.JSON(apiErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// errManagedBadge is returned for KubeBadges that the annotations of a
+// workload manage, which would revert any change made here.
+var errManagedBadge = errors.New("badge is managed by annotations")
+
+func errForeignBadge(namespace string) error {
+	return fmt.Errorf("badges in namespace %s can only be for resources of that namespace", namespace)
+}
+
+func badgeNamespace(c *gin.Context) string {
+	return c.DefaultQuery("namespace", config.KubeBadgeNamespace)
+}
+
+func mergeSpecPatch(current v1.KubeBadgeSpec, patch json.RawMessage) (v1.KubeBadgeSpec, error) {
+	if len(patch) == 0 {
+		return current, errors.New("spec is required")
+	}
+	original, err := json.Marshal(current)
+	if err != nil {
+		return current, err
+	}
+	merged, err := jsonpatch.MergePatch(original, patch)
+	if err != nil {
+		return current, err
+	}
+	var spec v1.KubeBadgeSpec
+	err = json.Unmarshal(merged, &spec)
+	return spec, err
+}
+
+// apiErrorStatus passes on the status code of Kubernetes API errors, so that
+// conflicts surface as 409 and missing badges as 404.
+func apiErrorStatus(err error) int {
+	var status apierrors.APIStatus
+	if errors.As(err, &status) && status.Status().Code != 0 {
+		return int(status.Status().Code)
+	}
+	return http.StatusInternalServerError
+}
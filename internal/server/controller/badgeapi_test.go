@@ -0,0 +1,210 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kubebadges/kubebadges/internal/auth"
+	"github.com/kubebadges/kubebadges/internal/server/middleware"
+	v1 "github.com/kubebadges/kubebadges/pkg/apis/kubebadges/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestFilterBadges(t *testing.T) {
+	newKubeBadge := func(namespace, name, badgeType, owner string, allowed bool) *v1.KubeBadge {
+		return &v1.KubeBadge{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+			Spec:       v1.KubeBadgeSpec{Type: badgeType, OwnerNamespace: owner, Allowed: allowed},
+		}
+	}
+	kubeBadges := []*v1.KubeBadge{
+		newKubeBadge("shop", "kube-deployment-shop-web", "deployment", "shop", true),
+		newKubeBadge("kubebadges", "kube-job-shop-migrate", "job", "shop", false),
+		newKubeBadge("kubebadges", "kube-deployment-billing-api", "deployment", "billing", true),
+	}
+	yes, no := true, false
+
+	tests := []struct {
+		name           string
+		namespace      string
+		badgeType      string
+		ownerNamespace string
+		allowed        *bool
+		want           []string
+	}{
+		{"all, sorted", "", "", "", nil, []string{"kube-deployment-billing-api", "kube-job-shop-migrate", "kube-deployment-shop-web"}},
+		{"by namespace", "shop", "", "", nil, []string{"kube-deployment-shop-web"}},
+		{"by type", "", "deployment", "", nil, []string{"kube-deployment-billing-api", "kube-deployment-shop-web"}},
+		{"by owner namespace", "", "", "shop", nil, []string{"kube-job-shop-migrate", "kube-deployment-shop-web"}},
+		{"allowed", "", "", "", &yes, []string{"kube-deployment-billing-api", "kube-deployment-shop-web"}},
+		{"not allowed", "", "", "", &no, []string{"kube-job-shop-migrate"}},
+		{"no match", "", "cronjob", "", nil, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := []string{}
+			for _, badge := range filterBadges(kubeBadges, tt.namespace, tt.badgeType, tt.ownerNamespace, tt.allowed) {
+				got = append(got, badge.Name)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("filterBadges() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeSpecPatch(t *testing.T) {
+	current := v1.KubeBadgeSpec{
+		Type:        "deployment",
+		OriginalURL: "/kube/deployment/shop/api",
+		DisplayName: "API",
+		AliasURL:    "api",
+		Group:       v1.Group{Kinds: []string{"deployment"}},
+	}
+
+	tests := []struct {
+		name    string
+		patch   string
+		want    func(spec v1.KubeBadgeSpec) v1.KubeBadgeSpec
+		wantErr bool
+	}{
+		{
+			name:  "set fields",
+			patch: `{"allowed": true, "displayName": "Checkout API"}`,
+			want: func(spec v1.KubeBadgeSpec) v1.KubeBadgeSpec {
+				spec.Allowed = true
+				spec.DisplayName = "Checkout API"
+				return spec
+			},
+		},
+		{
+			name:  "remove field",
+			patch: `{"aliasURL": null}`,
+			want: func(spec v1.KubeBadgeSpec) v1.KubeBadgeSpec {
+				spec.AliasURL = ""
+				return spec
+			},
+		},
+		{
+			name:  "nested field",
+			patch: `{"group": {"aggregation": "majority"}}`,
+			want: func(spec v1.KubeBadgeSpec) v1.KubeBadgeSpec {
+				spec.Group = v1.Group{Kinds: []string{"deployment"}, Aggregation: "majority"}
+				return spec
+			},
+		},
+		{name: "empty", patch: ``, wantErr: true},
+		{name: "invalid", patch: `{"allowed": "yes"}`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := mergeSpecPatch(current, []byte(tt.patch))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("mergeSpecPatch() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if want := tt.want(*current.DeepCopy()); !reflect.DeepEqual(got, want) {
+				t.Errorf("mergeSpecPatch() = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestApiErrorStatus(t *testing.T) {
+	resource := schema.GroupResource{Group: "kubebadges.tcode.ltd", Resource: "kubebadges"}
+
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"not found", apierrors.NewNotFound(resource, "api"), http.StatusNotFound},
+		{"conflict", apierrors.NewConflict(resource, "api", errors.New("modified")), http.StatusConflict},
+		{"already exists", apierrors.NewAlreadyExists(resource, "api"), http.StatusConflict},
+		{"other", errors.New("connection refused"), http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := apiErrorStatus(tt.err); got != tt.want {
+				t.Errorf("apiErrorStatus() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateBadgeForeignNamespace(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/api/badges", (&KubeController{}).CreateBadge)
+
+	for _, body := range []string{
+		`{"namespace": "shop", "spec": {"originalURL": "/kube/deployment/billing/api"}}`,
+		`{"namespace": "shop", "spec": {"originalURL": "/kube/deployment/shop/api", "ownerNamespace": "billing"}}`,
+		`{"namespace": "shop", "spec": {"originalURL": "/probe/api"}}`,
+	} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/badges", strings.NewReader(body)))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("CreateBadge(%s) status = %d, want %d", body, w.Code, http.StatusBadRequest)
+		}
+	}
+}
+
+type namespaceAuthorizer map[string]bool // key is namespace + " " + verb
+
+func (a namespaceAuthorizer) Authorize(_ context.Context, _ *auth.User, namespace, verb string) (bool, error) {
+	return a[namespace+" "+verb], nil
+}
+
+func TestReadableBadges(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	kubeBadges := []*v1.KubeBadge{
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "kubebadges", Name: "kube-deployment-billing-api"}},
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "kube-deployment-shop-api"}},
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "billing", Name: "kube-deployment-billing-web"}},
+	}
+	router := gin.New()
+	api := router.Group("/api", middleware.AuthMiddleware(auth.StaticTokens{"team-token": {Name: "team"}},
+		namespaceAuthorizer{"shop list": true, "shop get": true}))
+	api.GET("/badges", func(c *gin.Context) {
+		readable, ok := readableBadges(c, kubeBadges)
+		if !ok {
+			return
+		}
+		names := []string{}
+		for _, kubeBadge := range readable {
+			names = append(names, kubeBadge.Namespace+"/"+kubeBadge.Name)
+		}
+		c.JSON(http.StatusOK, names)
+	})
+	api.GET("/badges/:name", (&KubeController{}).GetBadge)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/api/badges", nil)
+	req.Header.Set("Authorization", "Bearer team-token")
+	router.ServeHTTP(w, req)
+	var names []string
+	_ = json.Unmarshal(w.Body.Bytes(), &names)
+	if want := []string{"shop/kube-deployment-shop-api"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("readableBadges() = %v, want %v", names, want)
+	}
+
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/api/badges/kube-deployment-billing-web?namespace=billing", nil)
+	req.Header.Set("Authorization", "Bearer team-token")
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("GetBadge() in another namespace status = %d, want %d", w.Code, http.StatusForbidden)
+	}
+}
@@ -1,359 +1,727 @@
 package controller
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"math"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/kubebadges/kubebadges/internal/badges"
-	"github.com/kubebadges/kubebadges/internal/cache"
+	"github.com/kubebadges/kubebadges/internal/model"
+	"github.com/kubebadges/kubebadges/internal/probe"
+	"github.com/kubebadges/kubebadges/internal/server/middleware"
+	"github.com/kubebadges/kubebadges/internal/service"
 	v1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/util/jsonpath"
 )
 
 // =============================================================
 // BadgesController
 // =============================================================
+// BadgesController computes badge states on every request. The resources are
+// read from the informer caches in KubeHelper, so no extra caching is needed.
 type BadgesController struct {
 	BaseController
-	namespaceCache  *cache.Cache[string, BadgeMessage]
-	deploymentCache *cache.Cache[string, BadgeMessage]
-	nodeCache       *cache.Cache[string, BadgeMessage]
-	podCache           *cache.Cache[string, BadgeMessage]
-	kustomizationCache *cache.Cache[string, BadgeMessage]
-	postgresqlCache    *cache.Cache[string, BadgeMessage]
-	jobCache           *cache.Cache[string, BadgeMessage]
 }
 
 func NewBadgesController(base *BaseController) *BadgesController {
 	return &BadgesController{
 		BaseController: *base,
-		namespaceCache:     cache.NewCache[string, BadgeMessage](),
-		deploymentCache:    cache.NewCache[string, BadgeMessage](),
-		nodeCache:          cache.NewCache[string, BadgeMessage](),
-		podCache:           cache.NewCache[string, BadgeMessage](),
-		kustomizationCache: cache.NewCache[string, BadgeMessage](),
-		postgresqlCache:    cache.NewCache[string, BadgeMessage](),
-		jobCache:           cache.NewCache[string, BadgeMessage](),
 	}
 }
 
-func (s *BadgesController) getCacheDuration() time.Duration {
-	return time.Duration(s.Config.CacheTime) * time.Second
+// serve writes the badge computed by badgeFunc, or the not found badge when
+// the resource does not exist.
+func (s *BadgesController) serve(c *gin.Context, badgeFunc func() (BadgeMessage, error)) {
+	badgeMessage, err := badgeFunc()
+	if err != nil {
+		s.NotFound(c)
+		return
+	}
+	s.Success(c, badgeMessage)
 }
 
 // Node badge
 func (s *BadgesController) Node(c *gin.Context) {
-	name := c.Param("node")
-	badgeMessage, ok := s.nodeCache.Get(name)
-	if !ok {
-		node, err := s.KubeHelper.GetNode(name)
-		if err != nil {
-			s.NotFound(c)
-			return
-		}
+	s.serve(c, func() (BadgeMessage, error) {
+		return s.nodeBadge(c.Param("node"))
+	})
+}
 
-		badgeMessage = BadgeMessage{
-			Key:   fmt.Sprintf("/kube/node/%s", name),
-			Label: name,
+func (s *BadgesController) nodeBadge(name string) (BadgeMessage, error) {
+	node, err := s.KubeHelper.GetNode(name)
+	if err != nil {
+		return BadgeMessage{}, err
+	}
+
+	badgeMessage := BadgeMessage{
+		Key:   fmt.Sprintf("/kube/node/%s", name),
+		Label: name,
+	}
+
+	isNodeReady := false
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == corev1.NodeReady && condition.Status == corev1.ConditionTrue {
+			isNodeReady = true
+			badgeMessage.MessageColor = badges.Green
+			badgeMessage.Message = string(condition.Type)
+			break
 		}
+	}
 
-		isNodeReady := false
+	if isNodeReady {
 		for _, condition := range node.Status.Conditions {
-			if condition.Type == corev1.NodeReady && condition.Status == corev1.ConditionTrue {
-				isNodeReady = true
-				badgeMessage.MessageColor = badges.Green
+			if condition.Type != corev1.NodeReady && condition.Status == corev1.ConditionTrue {
+				badgeMessage.MessageColor = badges.Yellow
 				badgeMessage.Message = string(condition.Type)
 				break
 			}
 		}
-
-		if isNodeReady {
-			for _, condition := range node.Status.Conditions {
-				if condition.Type != corev1.NodeReady && condition.Status == corev1.ConditionTrue {
-					badgeMessage.MessageColor = badges.Yellow
-					badgeMessage.Message = string(condition.Type)
-					break
-				}
-			}
-		} else {
-			badgeMessage.MessageColor = badges.Red
-			badgeMessage.Message = "NotReady"
-		}
-
-		s.nodeCache.Set(name, badgeMessage, s.getCacheDuration())
+	} else {
+		badgeMessage.MessageColor = badges.Red
+		badgeMessage.Message = "NotReady"
 	}
 
-	s.Success(c, badgeMessage)
+	return badgeMessage, nil
 }
 
 // Namespace badge
 func (s *BadgesController) Namespace(c *gin.Context) {
-	name := c.Param("namespace")
-	badgeMessage, ok := s.namespaceCache.Get(name)
-	if !ok {
-		namespace, err := s.KubeHelper.GetNamespace(name)
-		if err != nil {
-			s.NotFound(c)
-			return
-		}
+	s.serve(c, func() (BadgeMessage, error) {
+		return s.namespaceBadge(c.Param("namespace"))
+	})
+}
 
-		badgeMessage = BadgeMessage{
-			Key:     fmt.Sprintf("/kube/namespace/%s", name),
-			Label:   name,
-			Message: string(namespace.Status.Phase),
-		}
+func (s *BadgesController) namespaceBadge(name string) (BadgeMessage, error) {
+	namespace, err := s.KubeHelper.GetNamespace(name)
+	if err != nil {
+		return BadgeMessage{}, err
+	}
 
-		switch badgeMessage.Message {
-		case string(corev1.NamespaceActive):
-			badgeMessage.MessageColor = badges.Green
-		case string(corev1.NamespaceTerminating):
-			badgeMessage.MessageColor = badges.Red
-		default:
-			badgeMessage.MessageColor = badges.Blue
-		}
-		s.namespaceCache.Set(name, badgeMessage, s.getCacheDuration())
+	badgeMessage := BadgeMessage{
+		Key:     fmt.Sprintf("/kube/namespace/%s", name),
+		Label:   name,
+		Message: string(namespace.Status.Phase),
 	}
 
-	s.Success(c, badgeMessage)
+	switch badgeMessage.Message {
+	case string(corev1.NamespaceActive):
+		badgeMessage.MessageColor = badges.Green
+	case string(corev1.NamespaceTerminating):
+		badgeMessage.MessageColor = badges.Red
+	default:
+		badgeMessage.MessageColor = badges.Blue
+	}
+
+	return badgeMessage, nil
 }
 
 // Deployment badge
 func (s *BadgesController) Deployment(c *gin.Context) {
-	namespace := c.Param("namespace")
-	deploymentName := c.Param("deployment")
+	s.serve(c, func() (BadgeMessage, error) {
+		return s.deploymentBadge(c.Param("namespace"), c.Param("deployment"))
+	})
+}
 
-	badgeMessage, ok := s.deploymentCache.Get(fmt.Sprintf("%s_%s", namespace, deploymentName))
-	if !ok {
-		deployment, err := s.KubeHelper.GetDeployment(namespace, deploymentName)
-		if err != nil {
-			s.NotFound(c)
-			return
-		}
-		badgeMessage = BadgeMessage{
-			Key:   fmt.Sprintf("/kube/deployment/%s/%s", namespace, deploymentName),
-			Label: deploymentName,
-		}
-		statusMessage := ""
-		available := true
-		replicaFailure := false
-		for _, condition := range deployment.Status.Conditions {
-			if condition.Type == v1.DeploymentAvailable {
-				available = condition.Status == corev1.ConditionTrue
-			} else if condition.Type == v1.DeploymentReplicaFailure {
-				replicaFailure = condition.Status == corev1.ConditionTrue
-			}
-		}
+func (s *BadgesController) deploymentBadge(namespace, deploymentName string) (BadgeMessage, error) {
+	deployment, err := s.KubeHelper.GetDeployment(namespace, deploymentName)
+	if err != nil {
+		return BadgeMessage{}, err
+	}
+	badgeMessage := BadgeMessage{
+		Key:   fmt.Sprintf("/kube/deployment/%s/%s", namespace, deploymentName),
+		Label: deploymentName,
+	}
+	badgeMessage.Message, badgeMessage.MessageColor = deploymentStatus(deployment)
 
-		if available && !replicaFailure {
-			statusMessage = "Available"
-		} else if available && replicaFailure {
-			statusMessage = "Warning"
-		} else if !available && !replicaFailure {
-			statusMessage = "Unavailable"
-		} else if !available && replicaFailure {
-			statusMessage = "Failed"
-		}
+	return badgeMessage, nil
+}
 
-		switch statusMessage {
-		case "Available":
-			badgeMessage.MessageColor = badges.Green
-		case "Warning":
-			badgeMessage.MessageColor = badges.Yellow
-		case "Unavailable":
-			badgeMessage.MessageColor = badges.Red
-		case "Failed":
-			badgeMessage.MessageColor = badges.Red
-		default:
-			badgeMessage.MessageColor = badges.Blue
+func deploymentStatus(deployment *v1.Deployment) (string, string) {
+	statusMessage := ""
+	available := true
+	replicaFailure := false
+	for _, condition := range deployment.Status.Conditions {
+		if condition.Type == v1.DeploymentAvailable {
+			available = condition.Status == corev1.ConditionTrue
+		} else if condition.Type == v1.DeploymentReplicaFailure {
+			replicaFailure = condition.Status == corev1.ConditionTrue
 		}
+	}
 
-		if deployment.Status.AvailableReplicas != deployment.Status.Replicas {
-			badgeMessage.MessageColor = badges.Yellow
-		}
+	if available && !replicaFailure {
+		statusMessage = "Available"
+	} else if available && replicaFailure {
+		statusMessage = "Warning"
+	} else if !available && !replicaFailure {
+		statusMessage = "Unavailable"
+	} else if !available && replicaFailure {
+		statusMessage = "Failed"
+	}
 
-		badgeMessage.Message = fmt.Sprintf("%d/%d %s", deployment.Status.AvailableReplicas, deployment.Status.Replicas, statusMessage)
-		s.deploymentCache.Set(fmt.Sprintf("%s_%s", namespace, deploymentName), badgeMessage, s.getCacheDuration())
+	var color string
+	switch statusMessage {
+	case "Available":
+		color = badges.Green
+	case "Warning":
+		color = badges.Yellow
+	case "Unavailable":
+		color = badges.Red
+	case "Failed":
+		color = badges.Red
+	default:
+		color = badges.Blue
 	}
 
-	s.Success(c, badgeMessage)
+	if deployment.Status.AvailableReplicas != deployment.Status.Replicas {
+		color = badges.Yellow
+	}
+
+	return fmt.Sprintf("%d/%d %s", deployment.Status.AvailableReplicas, deployment.Status.Replicas, statusMessage), color
+}
+
+// workloadStatus builds the message and color shared by the StatefulSet and
+// DaemonSet badges from their ready and desired replica counts.
+func workloadStatus(ready, desired int32, rollingOut bool) (string, string) {
+	switch {
+	case desired == 0:
+		return "0/0 ScaledDown", badges.Blue
+	case rollingOut:
+		return fmt.Sprintf("%d/%d Updating", ready, desired), badges.Yellow
+	case ready == desired:
+		return fmt.Sprintf("%d/%d Ready", ready, desired), badges.Green
+	case ready == 0:
+		return fmt.Sprintf("%d/%d Unavailable", ready, desired), badges.Red
+	default:
+		return fmt.Sprintf("%d/%d Degraded", ready, desired), badges.Yellow
+	}
+}
+
+// StatefulSet badge
+func (s *BadgesController) StatefulSet(c *gin.Context) {
+	s.serve(c, func() (BadgeMessage, error) {
+		return s.statefulSetBadge(c.Param("namespace"), c.Param("statefulset"))
+	})
+}
+
+func (s *BadgesController) statefulSetBadge(namespace, statefulSetName string) (BadgeMessage, error) {
+	statefulSet, err := s.KubeHelper.GetStatefulSet(namespace, statefulSetName)
+	if err != nil {
+		return BadgeMessage{}, err
+	}
+
+	badgeMessage := BadgeMessage{
+		Key:   fmt.Sprintf("/kube/statefulset/%s/%s", namespace, statefulSetName),
+		Label: statefulSetName,
+	}
+	badgeMessage.Message, badgeMessage.MessageColor = statefulSetStatus(statefulSet)
+
+	return badgeMessage, nil
+}
+
+func statefulSetStatus(statefulSet *v1.StatefulSet) (string, string) {
+	desired := int32(1)
+	if statefulSet.Spec.Replicas != nil {
+		desired = *statefulSet.Spec.Replicas
+	}
+	rollingOut := statefulSet.Status.ObservedGeneration < statefulSet.Generation ||
+		statefulSet.Status.UpdatedReplicas < desired ||
+		(statefulSet.Status.UpdateRevision != "" && statefulSet.Status.CurrentRevision != statefulSet.Status.UpdateRevision)
+
+	return workloadStatus(statefulSet.Status.ReadyReplicas, desired, rollingOut)
+}
+
+// DaemonSet badge
+func (s *BadgesController) DaemonSet(c *gin.Context) {
+	s.serve(c, func() (BadgeMessage, error) {
+		return s.daemonSetBadge(c.Param("namespace"), c.Param("daemonset"))
+	})
+}
+
+func (s *BadgesController) daemonSetBadge(namespace, daemonSetName string) (BadgeMessage, error) {
+	daemonSet, err := s.KubeHelper.GetDaemonSet(namespace, daemonSetName)
+	if err != nil {
+		return BadgeMessage{}, err
+	}
+
+	badgeMessage := BadgeMessage{
+		Key:   fmt.Sprintf("/kube/daemonset/%s/%s", namespace, daemonSetName),
+		Label: daemonSetName,
+	}
+	badgeMessage.Message, badgeMessage.MessageColor = daemonSetStatus(daemonSet)
+
+	return badgeMessage, nil
+}
+
+func daemonSetStatus(daemonSet *v1.DaemonSet) (string, string) {
+	desired := daemonSet.Status.DesiredNumberScheduled
+	rollingOut := daemonSet.Status.ObservedGeneration < daemonSet.Generation ||
+		daemonSet.Status.UpdatedNumberScheduled < desired
+
+	return workloadStatus(daemonSet.Status.NumberReady, desired, rollingOut)
 }
 
 // Pod badge
 func (s *BadgesController) Pod(c *gin.Context) {
-	namespace := c.Param("namespace")
-	podName := c.Param("pod")
+	s.serve(c, func() (BadgeMessage, error) {
+		return s.podBadge(c.Param("namespace"), c.Param("pod"))
+	})
+}
 
-	badgeMessage, ok := s.podCache.Get(fmt.Sprintf("%s_%s", namespace, podName))
-	if !ok {
-		pod, err := s.KubeHelper.GetPod(namespace, podName)
-		if err != nil {
-			s.NotFound(c)
-			return
-		}
-		badgeMessage = BadgeMessage{
-			Key:     fmt.Sprintf("/kube/pod/%s/%s", namespace, podName),
-			Label:   podName,
-			Message: string(pod.Status.Phase),
-		}
+func (s *BadgesController) podBadge(namespace, podName string) (BadgeMessage, error) {
+	pod, err := s.KubeHelper.GetPod(namespace, podName)
+	if err != nil {
+		return BadgeMessage{}, err
+	}
+	badgeMessage := BadgeMessage{
+		Key:   fmt.Sprintf("/kube/pod/%s/%s", namespace, podName),
+		Label: podName,
+	}
+	badgeMessage.Message, badgeMessage.MessageColor = podStatus(pod)
 
-		switch badgeMessage.Message {
-		case string(corev1.PodRunning):
-			badgeMessage.MessageColor = badges.Green
-		case string(corev1.PodPending):
-			badgeMessage.MessageColor = badges.Yellow
-		case string(corev1.PodSucceeded):
-			badgeMessage.MessageColor = badges.Green
-		case string(corev1.PodFailed):
-			badgeMessage.MessageColor = badges.Red
-		case string(corev1.PodUnknown):
-			badgeMessage.MessageColor = badges.Blue
-		default:
-			badgeMessage.MessageColor = badges.Blue
-		}
+	return badgeMessage, nil
+}
 
-		s.podCache.Set(fmt.Sprintf("%s_%s", namespace, podName), badgeMessage, s.getCacheDuration())
+func podStatus(pod *corev1.Pod) (string, string) {
+	switch pod.Status.Phase {
+	case corev1.PodRunning:
+		return string(pod.Status.Phase), badges.Green
+	case corev1.PodPending:
+		return string(pod.Status.Phase), badges.Yellow
+	case corev1.PodSucceeded:
+		return string(pod.Status.Phase), badges.Green
+	case corev1.PodFailed:
+		return string(pod.Status.Phase), badges.Red
+	default:
+		return string(pod.Status.Phase), badges.Blue
 	}
-
-	s.Success(c, badgeMessage)
 }
 
 func (s *BadgesController) Job(c *gin.Context) {
-	namespace := c.Param("namespace")
-	jobName := c.Param("job")
+	s.serve(c, func() (BadgeMessage, error) {
+		return s.jobBadge(c.Param("namespace"), c.Param("job"))
+	})
+}
 
+func (s *BadgesController) jobBadge(namespace, jobName string) (BadgeMessage, error) {
 	key := fmt.Sprintf("/kube/job/%s/%s", namespace, jobName)
-	badgeMessage, ok := s.jobCache.Get(key)
-	if !ok {
-		job, err := s.KubeHelper.GetJob(namespace, jobName)
-		if err != nil {
-			s.NotFound(c)
-			return
-		}
+	job, err := s.KubeHelper.GetJob(namespace, jobName)
+	if err != nil {
+		return BadgeMessage{}, err
+	}
 
-		label := jobName
-		message := "Unknown"
-		messageColor := badges.Blue
-
-		if job.Status.Succeeded > 0 {
-			message = "Succeeded"
-			messageColor = badges.Green
-		} else if job.Status.Failed > 0 {
-			message = "Failed"
-			messageColor = badges.Red
-		} else if job.Status.Active > 0 {
-			message = "Active"
-			messageColor = badges.Yellow
-		}
+	badgeMessage := BadgeMessage{
+		Key:   key,
+		Label: jobName,
+	}
+	badgeMessage.Message, badgeMessage.MessageColor = jobStatus(job)
+
+	return badgeMessage, nil
+}
 
-		badgeMessage = BadgeMessage{
-			Key:          key,
-			Label:        label,
-			Message:      message,
-			MessageColor: messageColor,
+func jobStatus(job *batchv1.Job) (string, string) {
+	if job.Status.Succeeded > 0 {
+		return "Succeeded", badges.Green
+	} else if job.Status.Failed > 0 {
+		return "Failed", badges.Red
+	} else if job.Status.Active > 0 {
+		return "Active", badges.Yellow
+	}
+	return "Unknown", badges.Blue
+}
+
+// CronJob badge
+func (s *BadgesController) CronJob(c *gin.Context) {
+	maxAge := time.Duration(s.Config.CronJobMaxAge) * time.Second
+	if value, err := time.ParseDuration(c.Query("max_age")); err == nil {
+		maxAge = value
+	}
+
+	s.serve(c, func() (BadgeMessage, error) {
+		return s.cronJobBadge(c.Param("namespace"), c.Param("cronjob"), maxAge)
+	})
+}
+
+func (s *BadgesController) cronJobBadge(namespace, cronJobName string, maxAge time.Duration) (BadgeMessage, error) {
+	cronJob, err := s.KubeHelper.GetCronJob(namespace, cronJobName)
+	if err != nil {
+		return BadgeMessage{}, err
+	}
+	jobs, err := s.KubeHelper.GetOwnedJobs(namespace, cronJob.UID)
+	if err != nil {
+		return BadgeMessage{}, err
+	}
+
+	badgeMessage := BadgeMessage{
+		Key:   fmt.Sprintf("/kube/cronjob/%s/%s", namespace, cronJobName),
+		Label: cronJobName,
+	}
+	badgeMessage.Message, badgeMessage.MessageColor = cronJobStatus(cronJob, jobs, time.Now(), maxAge)
+
+	return badgeMessage, nil
+}
+
+// cronJobStatus reports the outcome of the most recent finished run. The
+// owned jobs are preferred, and the CronJob status is used once they have
+// been garbage-collected. A maxAge greater than zero turns the badge red when
+// the last successful run is older than maxAge.
+func cronJobStatus(cronJob *batchv1.CronJob, jobs []*batchv1.Job, now time.Time, maxAge time.Duration) (string, string) {
+	if cronJob.Spec.Suspend != nil && *cronJob.Spec.Suspend {
+		return "suspended", badges.LightGrey
+	}
+
+	var lastJob *batchv1.Job
+	var lastFinished time.Time
+	lastFailed := false
+	for _, job := range jobs {
+		for _, condition := range job.Status.Conditions {
+			if condition.Status != corev1.ConditionTrue ||
+				(condition.Type != batchv1.JobComplete && condition.Type != batchv1.JobFailed) {
+				continue
+			}
+			if lastJob == nil || condition.LastTransitionTime.After(lastFinished) {
+				lastJob = job
+				lastFinished = condition.LastTransitionTime.Time
+				lastFailed = condition.Type == batchv1.JobFailed
+			}
 		}
+	}
 
-		s.jobCache.Set(key, badgeMessage, s.getCacheDuration())
+	var lastSuccess time.Time
+	if cronJob.Status.LastSuccessfulTime != nil {
+		lastSuccess = cronJob.Status.LastSuccessfulTime.Time
 	}
+	stale := maxAge > 0 && !lastSuccess.IsZero() && now.Sub(lastSuccess) > maxAge
+
+	switch {
+	case lastJob != nil && lastFailed && lastFinished.After(lastSuccess):
+		return "failed " + formatAge(now.Sub(lastFinished)), badges.Red
+	case !lastSuccess.IsZero() && stale:
+		return "succeeded " + formatAge(now.Sub(lastSuccess)), badges.Red
+	case !lastSuccess.IsZero():
+		return "succeeded " + formatAge(now.Sub(lastSuccess)), badges.Green
+	case maxAge > 0 && now.Sub(cronJob.CreationTimestamp.Time) > maxAge:
+		return "never succeeded", badges.Red
+	case len(cronJob.Status.Active) > 0:
+		return "running", badges.Blue
+	case cronJob.Status.LastScheduleTime != nil:
+		return "scheduled " + formatAge(now.Sub(cronJob.Status.LastScheduleTime.Time)), badges.Blue
+	default:
+		return "never run", badges.LightGrey
+	}
+}
 
-	s.Success(c, badgeMessage)
+// formatAge renders a duration in the coarse "3h ago" form used by badges.
+func formatAge(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d/time.Minute))
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d/time.Hour))
+	default:
+		return fmt.Sprintf("%dd ago", int(d/(24*time.Hour)))
+	}
 }
 
 func (s *BadgesController) Postgresql(c *gin.Context) {
-	namespace := c.Param("namespace")
-	postgresqlName := c.Param("postgresql")
+	s.serve(c, func() (BadgeMessage, error) {
+		return s.postgresqlBadge(c.Param("namespace"), c.Param("postgresql"))
+	})
+}
 
+func (s *BadgesController) postgresqlBadge(namespace, postgresqlName string) (BadgeMessage, error) {
 	key := fmt.Sprintf("/kube/postgresql/%s/%s", namespace, postgresqlName)
-	badgeMessage, ok := s.postgresqlCache.Get(key)
-	if !ok {
-		postgresql, err := s.KubeHelper.GetPostgresql(namespace, postgresqlName)
-		if err != nil {
-			s.NotFound(c)
-			return
-		}
+	postgresql, err := s.KubeHelper.GetPostgresql(namespace, postgresqlName)
+	if err != nil {
+		return BadgeMessage{}, err
+	}
 
-		label := postgresqlName
-		message := "Unknown"
-		messageColor := badges.Blue
-
-		if status, ok := postgresql["status"].(map[string]interface{}); ok {
-			if clusterStatus, exists := status["PostgresClusterStatus"].(string); exists {
-				message = clusterStatus
-				switch clusterStatus {
-				case "Running":
-					messageColor = badges.Green
-				case "Creating":
-					messageColor = badges.Yellow
-				default:
-					messageColor = badges.Red
-				}
+	label := postgresqlName
+	message := "Unknown"
+	messageColor := badges.Blue
+
+	if status, ok := postgresql["status"].(map[string]interface{}); ok {
+		if clusterStatus, exists := status["PostgresClusterStatus"].(string); exists {
+			message = clusterStatus
+			switch clusterStatus {
+			case "Running":
+				messageColor = badges.Green
+			case "Creating":
+				messageColor = badges.Yellow
+			default:
+				messageColor = badges.Red
 			}
 		}
+	}
 
-		badgeMessage = BadgeMessage{
-			Key:          key,
-			Label:        label,
-			Message:      message,
-			MessageColor: messageColor,
-		}
-
-		s.postgresqlCache.Set(key, badgeMessage, s.getCacheDuration())
+	badgeMessage := BadgeMessage{
+		Key:          key,
+		Label:        label,
+		Message:      message,
+		MessageColor: messageColor,
 	}
 
-	s.Success(c, badgeMessage)
+	return badgeMessage, nil
 }
 
 func (s *BadgesController) Kustomization(c *gin.Context) {
-	namespace := c.Param("namespace")
-	kustomizationName := c.Param("kustomization")
+	s.serve(c, func() (BadgeMessage, error) {
+		return s.kustomizationBadge(c.Param("namespace"), c.Param("kustomization"))
+	})
+}
 
+func (s *BadgesController) kustomizationBadge(namespace, kustomizationName string) (BadgeMessage, error) {
 	key := fmt.Sprintf("/kube/kustomization/%s/%s", namespace, kustomizationName)
-	badgeMessage, ok := s.kustomizationCache.Get(key)
-	if !ok {
-		kustomization, err := s.KubeHelper.GetKustomization(namespace, kustomizationName)
-		if err != nil {
-			s.NotFound(c)
-			return
-		}
+	kustomization, err := s.KubeHelper.GetKustomization(namespace, kustomizationName)
+	if err != nil {
+		return BadgeMessage{}, err
+	}
 
-		// Parse .status.conditions to check if it's "Ready"
-		label := kustomizationName
-		message := "Unknown"
-		messageColor := badges.Blue
-
-		statusObj, hasStatus := kustomization["status"].(map[string]interface{})
-		if hasStatus {
-			if conditions, ok := statusObj["conditions"].([]interface{}); ok {
-				for _, cnd := range conditions {
-					cMap, ok := cnd.(map[string]interface{})
-					if !ok {
-						continue
-					}
-					cType, _ := cMap["type"].(string)
-					cStatus, _ := cMap["status"].(string)
-					if cType == "Ready" {
-						if cStatus == "True" {
-							message = "Ready"
-							messageColor = badges.Green
-						} else {
-							message = "NotReady"
-							messageColor = badges.Red
-						}
-						break
+	// Parse .status.conditions to check if it's "Ready"
+	label := kustomizationName
+	message := "Unknown"
+	messageColor := badges.Blue
+
+	statusObj, hasStatus := kustomization["status"].(map[string]interface{})
+	if hasStatus {
+		if conditions, ok := statusObj["conditions"].([]interface{}); ok {
+			for _, cnd := range conditions {
+				cMap, ok := cnd.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				cType, _ := cMap["type"].(string)
+				cStatus, _ := cMap["status"].(string)
+				if cType == "Ready" {
+					if cStatus == "True" {
+						message = "Ready"
+						messageColor = badges.Green
+					} else {
+						message = "NotReady"
+						messageColor = badges.Red
 					}
+					break
 				}
 			}
 		}
+	}
+
+	badgeMessage := BadgeMessage{
+		Key:          key,
+		Label:        label,
+		Message:      message,
+		MessageColor: messageColor,
+	}
+
+	return badgeMessage, nil
+}
+
+// Custom badge for any resource configured in the kubebadge-config ConfigMap
+func (s *BadgesController) Custom(c *gin.Context) {
+	s.serve(c, func() (BadgeMessage, error) {
+		return s.customBadge(c.Param("group"), c.Param("resource"), c.Param("namespace"), c.Param("name"))
+	})
+}
+
+func (s *BadgesController) customBadge(group, resource, namespace, name string) (BadgeMessage, error) {
+	definition, err := s.CustomResourcesService.GetDefinition(group, resource)
+	if err != nil {
+		return BadgeMessage{}, err
+	}
+	obj, err := s.KubeHelper.GetResource(definition.GVR(), namespace, name)
+	if err != nil {
+		return BadgeMessage{}, err
+	}
+
+	badgeMessage := BadgeMessage{
+		Key:   fmt.Sprintf("/kube/custom/%s/%s/%s/%s", group, resource, namespace, name),
+		Label: name,
+	}
+	badgeMessage.Message, badgeMessage.MessageColor = customResourceStatus(definition, obj)
+
+	return badgeMessage, nil
+}
+
+// customResourceStatus evaluates a definition against an object. Conditions
+// map their status ("True", "False", "Unknown") through the color table, and
+// JSONPath results map their string value.
+func customResourceStatus(definition *model.CustomResourceBadge, obj map[string]interface{}) (string, string) {
+	value := ""
+	message := "Unknown"
+
+	if len(definition.ConditionType) > 0 {
+		status, _ := obj["status"].(map[string]interface{})
+		conditions, _ := status["conditions"].([]interface{})
+		for _, cnd := range conditions {
+			cMap, ok := cnd.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if cType, _ := cMap["type"].(string); cType != definition.ConditionType {
+				continue
+			}
+			value, _ = cMap["status"].(string)
+			reason, _ := cMap["reason"].(string)
+			switch {
+			case value == string(corev1.ConditionTrue):
+				message = definition.ConditionType
+			case len(reason) > 0:
+				message = reason
+			default:
+				message = "Not" + definition.ConditionType
+			}
+			break
+		}
+	} else {
+		expression := definition.JSONPath
+		if !strings.HasPrefix(expression, "{") {
+			expression = "{" + expression + "}"
+		}
+		parser := jsonpath.New("badge").AllowMissingKeys(true)
+		var buf bytes.Buffer
+		if err := parser.Parse(expression); err == nil && parser.Execute(&buf, obj) == nil && buf.Len() > 0 {
+			value = buf.String()
+			message = value
+		}
+	}
+
+	if color, ok := definition.Colors[value]; ok {
+		return message, color
+	}
+	if len(definition.DefaultColor) > 0 {
+		return message, definition.DefaultColor
+	}
+	switch value {
+	case string(corev1.ConditionTrue):
+		return message, badges.Green
+	case string(corev1.ConditionFalse):
+		return message, badges.Red
+	default:
+		return message, badges.Blue
+	}
+}
+
+// Probe badge for a KubeBadge whose spec.custom describes an http, tcp or
+// grpc-health probe. The KubeBadge must be named probe-<name>.
+func (s *BadgesController) Probe(c *gin.Context) {
+	s.serve(c, func() (BadgeMessage, error) {
+		return s.probeBadge(c.Param("name"))
+	})
+}
+
+func (s *BadgesController) probeBadge(name string) (BadgeMessage, error) {
+	key := fmt.Sprintf("/probe/%s", name)
+
+	kubeBadge, err := s.KubeBadgesService.GetKubeBadge(key, false)
+	if err != nil {
+		return BadgeMessage{}, err
+	}
+	if !probe.Supported(kubeBadge.Spec.Custom.Type) {
+		return BadgeMessage{}, errors.New("not a probe")
+	}
+
+	label := kubeBadge.Spec.DisplayName
+	if len(label) == 0 {
+		label = name
+	}
+	badgeMessage := BadgeMessage{
+		Key:   key,
+		Label: label,
+	}
+	result, ok := s.ProbeService.GetResult(kubeBadge.Name)
+	badgeMessage.Message, badgeMessage.MessageColor = probeStatus(result, ok)
+
+	return badgeMessage, nil
+}
+
+func probeStatus(result probe.Result, ok bool) (string, string) {
+	switch {
+	case !ok:
+		return "pending", badges.LightGrey
+	case result.Up:
+		return fmt.Sprintf("up %dms", result.Latency.Milliseconds()), badges.Green
+	default:
+		return "down", badges.Red
+	}
+}
+
+// defaultUptimeWindow is used when the window query parameter is missing or
+// invalid.
+const defaultUptimeWindow = "30d"
+
+// Uptime badge, served under any badge URL followed by /uptime
+func (s *BadgesController) Uptime(c *gin.Context) {
+	key := strings.TrimSuffix(strings.TrimPrefix(c.Request.URL.Path, "/badges"), "/uptime")
+
+	windowName := c.DefaultQuery("window", defaultUptimeWindow)
+	window, err := service.ParseWindow(windowName)
+	if err != nil || window > service.HistoryRetention {
+		windowName = defaultUptimeWindow
+		window, _ = service.ParseWindow(windowName)
+	}
 
-		badgeMessage = BadgeMessage{
-			Key:          key,
-			Label:        label,
-			Message:      message,
-			MessageColor: messageColor,
+	s.serve(c, func() (BadgeMessage, error) {
+		if _, ok := parseBadgeKey(key); !ok {
+			return BadgeMessage{}, errUnknownBadgeKey
 		}
+		badgeMessage := BadgeMessage{
+			Key:   key + "/uptime",
+			Label: "uptime " + windowName,
+		}
+		badgeMessage.Message, badgeMessage.MessageColor = uptimeStatus(s.HistoryService.Uptime(key, window, time.Now()))
+		return badgeMessage, nil
+	})
+}
 
-		s.kustomizationCache.Set(key, badgeMessage, s.getCacheDuration())
+// uptimeStatus formats an uptime percentage, rounded down so that a badge
+// never claims 100% while there was down time.
+func uptimeStatus(percent float64, ok bool) (string, string) {
+	if !ok {
+		return "no data", badges.LightGrey
 	}
+	message := strconv.FormatFloat(math.Floor(percent*10)/10, 'f', -1, 64) + "%"
+	switch {
+	case percent >= 99.9:
+		return message, badges.BrightGreen
+	case percent >= 99:
+		return message, badges.Green
+	case percent >= 95:
+		return message, badges.Yellow
+	case percent >= 90:
+		return message, badges.Orange
+	default:
+		return message, badges.Red
+	}
+}
 
-	s.Success(c, badgeMessage)
+// Alias resolves an alias URL to the KubeBadge holding it and serves its
+// badge through the engine's regular badge routes.
+func (s *BadgesController) Alias(engine *gin.Engine) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// the access middleware has resolved the alias on the external engine
+		kubeBadge, ok := middleware.ResolvedKubeBadge(c)
+		var err error
+		if !ok {
+			kubeBadge, err = s.KubeBadgesService.GetKubeBadgeByAlias(c.Param("alias"))
+		}
+		if err != nil || !(strings.HasPrefix(kubeBadge.Spec.OriginalURL, "/kube/") ||
+			strings.HasPrefix(kubeBadge.Spec.OriginalURL, "/probe/") ||
+			strings.HasPrefix(kubeBadge.Spec.OriginalURL, "/group/")) {
+			s.NotFound(c)
+			return
+		}
+
+		// the badge routes use this KubeBadge rather than the one of its key
+		middleware.WithKubeBadge(c, kubeBadge)
+		c.Request.URL.Path = "/badges" + kubeBadge.Spec.OriginalURL
+		engine.HandleContext(c)
+	}
 }
@@ -0,0 +1,312 @@
+package controller
+
+import (
+	"testing"
+	"text/template"
+	"time"
+
+	"github.com/kubebadges/kubebadges/internal/badges"
+	"github.com/kubebadges/kubebadges/internal/model"
+	"github.com/kubebadges/kubebadges/internal/probe"
+	"github.com/kubebadges/kubebadges/internal/service"
+	v1 "github.com/kubebadges/kubebadges/pkg/apis/kubebadges/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestWorkloadStatus(t *testing.T) {
+	tests := []struct {
+		name        string
+		ready       int32
+		desired     int32
+		rollingOut  bool
+		wantMessage string
+		wantColor   string
+	}{
+		{"ready", 3, 3, false, "3/3 Ready", badges.Green},
+		{"rolling out", 3, 3, true, "3/3 Updating", badges.Yellow},
+		{"degraded", 1, 3, false, "1/3 Degraded", badges.Yellow},
+		{"unavailable", 0, 3, false, "0/3 Unavailable", badges.Red},
+		{"scaled down", 0, 0, false, "0/0 ScaledDown", badges.Blue},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotMessage, gotColor := workloadStatus(tt.ready, tt.desired, tt.rollingOut)
+			if gotMessage != tt.wantMessage {
+				t.Errorf("workloadStatus() gotMessage = %v, want %v", gotMessage, tt.wantMessage)
+			}
+			if gotColor != tt.wantColor {
+				t.Errorf("workloadStatus() gotColor = %v, want %v", gotColor, tt.wantColor)
+			}
+		})
+	}
+}
+
+func TestCronJobStatus(t *testing.T) {
+	now := time.Date(2024, 1, 2, 12, 0, 0, 0, time.UTC)
+	ago := func(d time.Duration) *metav1.Time {
+		v := metav1.NewTime(now.Add(-d))
+		return &v
+	}
+	finishedJob := func(conditionType batchv1.JobConditionType, d time.Duration) *batchv1.Job {
+		return &batchv1.Job{Status: batchv1.JobStatus{Conditions: []batchv1.JobCondition{
+			{Type: conditionType, Status: corev1.ConditionTrue, LastTransitionTime: *ago(d)},
+		}}}
+	}
+	suspend := true
+
+	tests := []struct {
+		name        string
+		cronJob     *batchv1.CronJob
+		jobs        []*batchv1.Job
+		maxAge      time.Duration
+		wantMessage string
+		wantColor   string
+	}{
+		{
+			name:        "suspended",
+			cronJob:     &batchv1.CronJob{Spec: batchv1.CronJobSpec{Suspend: &suspend}},
+			wantMessage: "suspended",
+			wantColor:   badges.LightGrey,
+		},
+		{
+			name:        "last job failed",
+			cronJob:     &batchv1.CronJob{Status: batchv1.CronJobStatus{LastSuccessfulTime: ago(26 * time.Hour)}},
+			jobs:        []*batchv1.Job{finishedJob(batchv1.JobFailed, 2*time.Hour)},
+			wantMessage: "failed 2h ago",
+			wantColor:   badges.Red,
+		},
+		{
+			name:        "succeeded from status after jobs are collected",
+			cronJob:     &batchv1.CronJob{Status: batchv1.CronJobStatus{LastSuccessfulTime: ago(3 * time.Hour)}},
+			wantMessage: "succeeded 3h ago",
+			wantColor:   badges.Green,
+		},
+		{
+			name:        "success older than max age",
+			cronJob:     &batchv1.CronJob{Status: batchv1.CronJobStatus{LastSuccessfulTime: ago(72 * time.Hour)}},
+			maxAge:      25 * time.Hour,
+			wantMessage: "succeeded 3d ago",
+			wantColor:   badges.Red,
+		},
+		{
+			name:        "never run",
+			cronJob:     &batchv1.CronJob{ObjectMeta: metav1.ObjectMeta{CreationTimestamp: *ago(time.Minute)}},
+			maxAge:      25 * time.Hour,
+			wantMessage: "never run",
+			wantColor:   badges.LightGrey,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotMessage, gotColor := cronJobStatus(tt.cronJob, tt.jobs, now, tt.maxAge)
+			if gotMessage != tt.wantMessage {
+				t.Errorf("cronJobStatus() gotMessage = %v, want %v", gotMessage, tt.wantMessage)
+			}
+			if gotColor != tt.wantColor {
+				t.Errorf("cronJobStatus() gotColor = %v, want %v", gotColor, tt.wantColor)
+			}
+		})
+	}
+}
+
+func TestFormatAge(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{30 * time.Second, "just now"},
+		{5 * time.Minute, "5m ago"},
+		{3 * time.Hour, "3h ago"},
+		{50 * time.Hour, "2d ago"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := formatAge(tt.d); got != tt.want {
+				t.Errorf("formatAge() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCustomResourceStatus(t *testing.T) {
+	certificate := map[string]interface{}{
+		"status": map[string]interface{}{
+			"phase": "Synced",
+			"conditions": []interface{}{
+				map[string]interface{}{"type": "Issuing", "status": "True"},
+				map[string]interface{}{"type": "Ready", "status": "False", "reason": "Expired"},
+			},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		definition  *model.CustomResourceBadge
+		wantMessage string
+		wantColor   string
+	}{
+		{
+			name:        "condition with default colors",
+			definition:  &model.CustomResourceBadge{ConditionType: "Ready"},
+			wantMessage: "Expired",
+			wantColor:   badges.Red,
+		},
+		{
+			name:        "condition true",
+			definition:  &model.CustomResourceBadge{ConditionType: "Issuing"},
+			wantMessage: "Issuing",
+			wantColor:   badges.Green,
+		},
+		{
+			name:        "missing condition",
+			definition:  &model.CustomResourceBadge{ConditionType: "Synced"},
+			wantMessage: "Unknown",
+			wantColor:   badges.Blue,
+		},
+		{
+			name: "jsonpath with color table",
+			definition: &model.CustomResourceBadge{
+				JSONPath: ".status.phase",
+				Colors:   map[string]string{"Synced": badges.Green, "OutOfSync": badges.Yellow},
+			},
+			wantMessage: "Synced",
+			wantColor:   badges.Green,
+		},
+		{
+			name:        "jsonpath default color",
+			definition:  &model.CustomResourceBadge{JSONPath: "{.status.phase}", DefaultColor: badges.LightGrey},
+			wantMessage: "Synced",
+			wantColor:   badges.LightGrey,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotMessage, gotColor := customResourceStatus(tt.definition, certificate)
+			if gotMessage != tt.wantMessage {
+				t.Errorf("customResourceStatus() gotMessage = %v, want %v", gotMessage, tt.wantMessage)
+			}
+			if gotColor != tt.wantColor {
+				t.Errorf("customResourceStatus() gotColor = %v, want %v", gotColor, tt.wantColor)
+			}
+		})
+	}
+}
+
+func TestProbeStatus(t *testing.T) {
+	tests := []struct {
+		name        string
+		result      probe.Result
+		ok          bool
+		wantMessage string
+		wantColor   string
+	}{
+		{"pending", probe.Result{}, false, "pending", badges.LightGrey},
+		{"up", probe.Result{Up: true, Latency: 42 * time.Millisecond}, true, "up 42ms", badges.Green},
+		{"down", probe.Result{Error: "connection refused"}, true, "down", badges.Red},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message, color := probeStatus(tt.result, tt.ok)
+			if message != tt.wantMessage || color != tt.wantColor {
+				t.Errorf("probeStatus() = %q, %q, want %q, %q", message, color, tt.wantMessage, tt.wantColor)
+			}
+		})
+	}
+}
+
+func TestParseBadgeKey(t *testing.T) {
+	tests := []struct {
+		key      string
+		wantKind string
+		wantNs   string
+		wantName string
+		wantOK   bool
+	}{
+		{"/kube/node/worker-1", "node", "", "worker-1", true},
+		{"/kube/summary/shop", "summary", "shop", "shop", true},
+		{"/kube/namespace/shop", "namespace", "", "shop", true},
+		{"/kube/deployment/shop/api", "deployment", "shop", "api", true},
+		{"/kube/pod/shop/api-123", "pod", "shop", "api-123", true},
+		{"/kube/statefulset/shop/postgres", "statefulset", "shop", "postgres", true},
+		{"/kube/daemonset/kube-system/fluent-bit", "daemonset", "kube-system", "fluent-bit", true},
+		{"/kube/cronjob/shop/nightly-backup", "cronjob", "shop", "nightly-backup", true},
+		{"/kube/custom/cert-manager.io/certificates/shop/tls", "certificates.cert-manager.io", "shop", "tls", true},
+		{"/kube/custom/core/services/shop/api", "services", "shop", "api", true},
+		{"/probe/api", "probe", "", "api", true},
+		{"/group/checkout", "group", "", "checkout", true},
+		{"/kube/deployment/shop", "", "", "", false},
+		{"/kube/deployment//api", "", "", "", false},
+		{"/kube/unknown/shop/api", "", "", "", false},
+		{"/other", "", "", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			got, ok := parseBadgeKey(tt.key)
+			if ok != tt.wantOK {
+				t.Fatalf("parseBadgeKey() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if !ok {
+				return
+			}
+			if got.metricKind() != tt.wantKind || got.namespace != tt.wantNs || got.name != tt.wantName {
+				t.Errorf("parseBadgeKey() = %s %s %s, want %s %s %s", got.metricKind(), got.namespace, got.name, tt.wantKind, tt.wantNs, tt.wantName)
+			}
+		})
+	}
+}
+
+func TestUptimeStatus(t *testing.T) {
+	tests := []struct {
+		name        string
+		percent     float64
+		ok          bool
+		wantMessage string
+		wantColor   string
+	}{
+		{"no data", 0, false, "no data", badges.LightGrey},
+		{"perfect", 100, true, "100%", badges.BrightGreen},
+		{"rounded down", 99.99, true, "99.9%", badges.BrightGreen},
+		{"good", 99.74, true, "99.7%", badges.Green},
+		{"degraded", 96, true, "96%", badges.Yellow},
+		{"poor", 91.25, true, "91.2%", badges.Orange},
+		{"down", 50, true, "50%", badges.Red},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message, color := uptimeStatus(tt.percent, tt.ok)
+			if message != tt.wantMessage || color != tt.wantColor {
+				t.Errorf("uptimeStatus() = %q, %q, want %q, %q", message, color, tt.wantMessage, tt.wantColor)
+			}
+		})
+	}
+}
+
+func TestApplyAppearance(t *testing.T) {
+	computed := BadgeMessage{Key: "/kube/deployment/shop/web", Label: "web", Message: "3/3 Ready", MessageColor: badges.Green}
+	tests := []struct {
+		name        string
+		appearance  v1.Appearance
+		wantMessage string
+		wantColor   string
+	}{
+		{"none", v1.Appearance{}, "3/3 Ready", badges.Green},
+		{"color by computed color", v1.Appearance{Colors: map[string]string{"green": "#0a7d3b"}}, "3/3 Ready", "#0a7d3b"},
+		{"message wins over color", v1.Appearance{Colors: map[string]string{"green": "#0a7d3b", "3/3 Ready": "blue"}}, "3/3 Ready", "blue"},
+		{"template", v1.Appearance{MessageTemplate: "{{.Message}} ({{.Color}})", Colors: map[string]string{"green": "teal"}}, "3/3 Ready (teal)", "teal"},
+		{"invalid template", v1.Appearance{MessageTemplate: "{{.Missing}}"}, "3/3 Ready", badges.Green},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var messageTemplate *template.Template
+			if len(tt.appearance.MessageTemplate) > 0 {
+				messageTemplate, _ = service.ParseMessageTemplate(tt.appearance.MessageTemplate)
+			}
+			got := applyAppearance(computed, tt.appearance, messageTemplate)
+			if got.Message != tt.wantMessage || got.MessageColor != tt.wantColor {
+				t.Errorf("applyAppearance() = %q %q, want %q %q", got.Message, got.MessageColor, tt.wantMessage, tt.wantColor)
+			}
+		})
+	}
+}
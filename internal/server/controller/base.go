@@ -1,11 +1,17 @@
 package controller
 
 import (
+	"bytes"
+	"log/slog"
 	"net/http"
+	"text/template"
 
 	"github.com/gin-gonic/gin"
 	"github.com/kubebadges/kubebadges/internal/badges"
+	"github.com/kubebadges/kubebadges/internal/server/middleware"
 	"github.com/kubebadges/kubebadges/internal/server/svc"
+	"github.com/kubebadges/kubebadges/internal/service"
+	v1 "github.com/kubebadges/kubebadges/pkg/apis/kubebadges/v1"
 )
 
 var notFoundSvg = `
@@ -44,41 +50,85 @@ type BaseController struct {
 }
 
 func (b *BaseController) NotFound(c *gin.Context) {
-	resultType := c.DefaultQuery("type", "svg")
-	if resultType == "json" {
+	format := badges.NegotiateFormat(c.Query("type"), c.GetHeader("Accept"))
+	switch format {
+	case badges.FormatJSON:
 		c.Header("Content-Type", "application/json")
 		c.AbortWithStatus(http.StatusOK)
-		return
+	case badges.FormatSVG:
+		c.Header("Content-Type", "image/svg+xml")
+		c.String(http.StatusNotFound, notFoundSvg)
+	default:
+		badge := badges.NewBadgeBuilder().
+			SetLabel("404").
+			SetMessage("badge not found").
+			SetMessageColor(badges.Red).
+			Build()
+		b.BadgesHelper.WriteError(badge, format, http.StatusNotFound, c)
 	}
-
-	c.Header("Content-Type", "image/svg+xml")
-	c.String(http.StatusNotFound, notFoundSvg)
 }
 
 func (b *BaseController) Success(c *gin.Context, badgeMessage BadgeMessage) {
-	if kubeBadge, err := b.KubeBadgesService.GetKubeBadge(badgeMessage.Key, false); err == nil {
+	var appearance v1.Appearance
+	var messageTemplate *template.Template
+	if kubeBadge, err := b.kubeBadge(c, badgeMessage.Key); err == nil {
 		if len(kubeBadge.Spec.DisplayName) > 0 {
 			badgeMessage.Label = kubeBadge.Spec.DisplayName
 		}
+		appearance = kubeBadge.Spec.Appearance
+		messageTemplate = b.KubeBadgesService.MessageTemplate(kubeBadge)
+	}
+	badgeMessage = applyAppearance(badgeMessage, appearance, messageTemplate)
+
+	style := c.Query("style")
+	if len(style) == 0 {
+		style = appearance.Style
 	}
 
 	badge := badges.NewBadgeBuilder().
 		SetLabel(badgeMessage.Label).
 		SetMessage(badgeMessage.Message).
 		SetMessageColor(badgeMessage.MessageColor).
-		SetStyle(c.Query("style")).
+		SetLabelColor(appearance.LabelColor).
+		SetLogo(appearance.Logo).
+		SetLogoSVG(appearance.LogoSVG).
+		SetStyle(style).
 		Build()
 
-	resultType := c.DefaultQuery("type", "svg")
-	if resultType == "json" {
-		c.Header("Content-Type", "application/json")
-		c.JSON(http.StatusOK, gin.H{
-			"label":   badge.Label,
-			"message": badge.Message,
-			"color":   badge.MessageColor,
-		})
-		return
+	format := badges.NegotiateFormat(c.Query("type"), c.GetHeader("Accept"))
+	b.BadgesHelper.WriteBadge(badge, format, c)
+}
+
+// kubeBadge returns the KubeBadge of a badge key, or the one the request
+// was resolved to, such as the holder of an alias. c may be nil outside of
+// a request.
+func (b *BaseController) kubeBadge(c *gin.Context, key string) (*v1.KubeBadge, error) {
+	if kubeBadge, ok := middleware.KubeBadgeOf(c, key); ok {
+		return kubeBadge, nil
+	}
+	return b.KubeBadgesService.GetKubeBadge(key, false)
+}
+
+// applyAppearance overrides the color and message of a badge as its
+// appearance asks. Colors are looked up by message first, then by the
+// computed color. messageTemplate is the parsed template of the appearance,
+// it sees the computed message and the color drawn; a template that fails
+// leaves the message as it was.
+func applyAppearance(badgeMessage BadgeMessage, appearance v1.Appearance, messageTemplate *template.Template) BadgeMessage {
+	if color, ok := appearance.Colors[badgeMessage.Message]; ok {
+		badgeMessage.MessageColor = color
+	} else if color, ok := appearance.Colors[badgeMessage.MessageColor]; ok {
+		badgeMessage.MessageColor = color
 	}
 
-	b.BadgesHelper.CreateBadgeProxy(badge, c)
+	if messageTemplate != nil {
+		var message bytes.Buffer
+		err := messageTemplate.Execute(&message, service.MessageTemplateData{Label: badgeMessage.Label, Message: badgeMessage.Message, Color: badgeMessage.MessageColor})
+		if err != nil {
+			slog.Warn("message template failed", "key", badgeMessage.Key, "error", err)
+		} else {
+			badgeMessage.Message = message.String()
+		}
+	}
+	return badgeMessage
 }
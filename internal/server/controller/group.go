@@ -0,0 +1,46 @@
+package controller
+
+import (
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// Group badge for a KubeBadge whose spec.group selects the objects to roll
+// up. The KubeBadge must be named group-<name>.
+func (s *BadgesController) Group(c *gin.Context) {
+	s.serve(c, func() (BadgeMessage, error) {
+		return s.groupBadge(c.Param("name"))
+	})
+}
+
+func (s *BadgesController) groupBadge(name string) (BadgeMessage, error) {
+	key := fmt.Sprintf("/group/%s", name)
+
+	kubeBadge, err := s.KubeBadgesService.GetKubeBadge(key, false)
+	if err != nil {
+		return BadgeMessage{}, err
+	}
+	group := kubeBadge.Spec.Group
+	selector, err := labels.Parse(group.Selector)
+	if err != nil {
+		return BadgeMessage{}, err
+	}
+	workloads, err := s.KubeHelper.GetWorkloads(group.Namespace, selector)
+	if err != nil {
+		return BadgeMessage{}, err
+	}
+
+	label := kubeBadge.Spec.DisplayName
+	if len(label) == 0 {
+		label = name
+	}
+	badgeMessage := BadgeMessage{
+		Key:   key,
+		Label: label,
+	}
+	badgeMessage.Message, badgeMessage.MessageColor = aggregateStatus(workloadMembers(workloads, labels.Nothing(), group.Kinds), group.Aggregation)
+
+	return badgeMessage, nil
+}
@@ -4,14 +4,19 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
-	"strings"
 	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/kubebadges/kubebadges/internal/auth"
 	"github.com/kubebadges/kubebadges/internal/cache"
+	"github.com/kubebadges/kubebadges/internal/config"
 	"github.com/kubebadges/kubebadges/internal/model"
+	"github.com/kubebadges/kubebadges/internal/server/middleware"
 	"github.com/kubebadges/kubebadges/internal/server/svc"
+	"github.com/kubebadges/kubebadges/internal/service"
+	"github.com/kubebadges/kubebadges/internal/signing"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 type KubeController struct {
@@ -111,6 +116,66 @@ func (s *KubeController) ListDeployments(c *gin.Context) {
 	c.JSON(http.StatusOK, s.populateKubeBadges(result))
 }
 
+func (s *KubeController) ListStatefulSets(c *gin.Context) {
+	namespace := c.Param("namespace")
+	key := fmt.Sprintf("statefulset_%s", namespace)
+
+	result, ok := s.cache.Get(key)
+	if !ok || c.Query("force") == "true" {
+		statefulSets, err := s.KubeHelper.GetStatefulSets(namespace)
+		if err != nil {
+			c.JSON(500, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+
+		tmp := make([]model.KubeBadges, len(statefulSets))
+		for i, sts := range statefulSets {
+			tmp[i] = model.KubeBadges{
+				Kind:  "statefulset",
+				Name:  sts.Name,
+				Key:   fmt.Sprintf("/kube/statefulset/%s/%s", namespace, sts.Name),
+				Badge: fmt.Sprintf("/badges/kube/statefulset/%s/%s", namespace, sts.Name),
+			}
+		}
+		result = tmp
+		s.cache.Set(key, result, time.Minute*2)
+	}
+
+	c.JSON(http.StatusOK, s.populateKubeBadges(result))
+}
+
+func (s *KubeController) ListDaemonSets(c *gin.Context) {
+	namespace := c.Param("namespace")
+	key := fmt.Sprintf("daemonset_%s", namespace)
+
+	result, ok := s.cache.Get(key)
+	if !ok || c.Query("force") == "true" {
+		daemonSets, err := s.KubeHelper.GetDaemonSets(namespace)
+		if err != nil {
+			c.JSON(500, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+
+		tmp := make([]model.KubeBadges, len(daemonSets))
+		for i, ds := range daemonSets {
+			tmp[i] = model.KubeBadges{
+				Kind:  "daemonset",
+				Name:  ds.Name,
+				Key:   fmt.Sprintf("/kube/daemonset/%s/%s", namespace, ds.Name),
+				Badge: fmt.Sprintf("/badges/kube/daemonset/%s/%s", namespace, ds.Name),
+			}
+		}
+		result = tmp
+		s.cache.Set(key, result, time.Minute*2)
+	}
+
+	c.JSON(http.StatusOK, s.populateKubeBadges(result))
+}
+
 func (s *KubeController) populateKubeBadges(result []model.KubeBadges) []model.KubeBadges {
 	var wg sync.WaitGroup
 	newResult := make([]model.KubeBadges, len(result))
@@ -138,6 +203,7 @@ type UpdateBadgeRequest struct {
 	DisplayName *string `json:"display_name"`
 	Alias       *string `json:"alias"`
 	Allowed     *bool   `json:"allowed"`
+	Notify      *bool   `json:"notify"`
 	Key         string  `json:"key"`
 }
 
@@ -147,12 +213,35 @@ func (s *KubeController) UpdateBadge(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	if req.Allowed == nil && req.DisplayName == nil && req.Alias == nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "at least one of allowed, display_name or alias should be provided"})
+	if req.Allowed == nil && req.DisplayName == nil && req.Alias == nil && req.Notify == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "at least one of allowed, display_name, alias or notify should be provided"})
+		return
+	}
+	parsed, ok := parseBadgeKey(req.Key)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errUnknownBadgeKey.Error()})
 		return
 	}
-
 	kubeBadge, err := s.KubeBadgesService.GetKubeBadge(req.Key, true)
+	namespace := config.KubeBadgeNamespace
+	if err == nil {
+		namespace = kubeBadge.Namespace
+	}
+	// a team's KubeBadge is changed with the permissions of that team
+	if namespace != config.KubeBadgeNamespace {
+		if metav1.GetControllerOf(kubeBadge) != nil {
+			c.JSON(http.StatusConflict, gin.H{"error": errManagedBadge.Error()})
+			return
+		}
+		if !middleware.AuthorizeIn(c, namespace, auth.VerbUpdate) {
+			return
+		}
+	}
+	// making a badge public is a separate permission from editing it
+	if req.Allowed != nil && *req.Allowed && !middleware.AuthorizeIn(c, namespace, auth.VerbPublish) {
+		return
+	}
+
 	if err != nil {
 		// create kubebadge CRD
 		spec := s.KubeBadgesService.CreateKubeBadgesSpec()
@@ -160,8 +249,8 @@ func (s *KubeController) UpdateBadge(c *gin.Context) {
 		spec.AliasURL = ""
 		spec.Allowed = false
 		spec.OriginalURL = req.Key
-		spec.Type, _, _ = s.parseKey(req.Key)
-		_, spec.OwnerNamespace, _ = s.parseKey(req.Key)
+		spec.Type = parsed.kind
+		spec.OwnerNamespace = parsed.namespace
 
 		kubeBadge, err = s.KubeBadgesService.CreateKubeBadge(spec)
 		if err != nil {
@@ -170,6 +259,8 @@ func (s *KubeController) UpdateBadge(c *gin.Context) {
 		}
 	}
 
+	// the cached object is shared with the informer
+	kubeBadge = kubeBadge.DeepCopy()
 	if req.Allowed != nil {
 		kubeBadge.Spec.Allowed = *req.Allowed
 	}
@@ -179,50 +270,19 @@ func (s *KubeController) UpdateBadge(c *gin.Context) {
 	if req.Alias != nil {
 		kubeBadge.Spec.AliasURL = *req.Alias
 	}
+	if req.Notify != nil {
+		kubeBadge.Spec.Notify = *req.Notify
+	}
 
 	_, err = s.KubeBadgesService.UpdateKubeBadge(kubeBadge)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(apiErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"status": "ok"})
 }
 
-func (s *KubeController) parseKey(key string) (resourceType string, namespace string, name string) {
-	segments := strings.Split(key, "/")
-	switch segments[2] {
-	case "node":
-		resourceType = "node"
-		name = segments[3]
-	case "namespace":
-		resourceType = "namespace"
-		name = segments[3]
-	case "deployment":
-		resourceType = "deployment"
-		namespace = segments[3]
-		name = segments[4]
-	case "pod":
-		resourceType = "pod"
-		namespace = segments[3]
-		name = segments[4]
-	case "kustomization":
-		resourceType = "kustomization"
-		namespace = segments[3]
-		name = segments[4]
-	case "job":
-		resourceType = "job"
-		namespace = segments[3]
-		name = segments[4]
-	case "postgresql":
-		resourceType = "postgresql"
-		namespace = segments[3]
-		name = segments[4]
-	}
-
-	return
-}
-
 func (s *KubeController) GetConfig(c *gin.Context) {
 	configMap, err := s.KubeHelper.GetOrCreateConfig()
 	if err != nil {
@@ -246,7 +306,11 @@ func (s *KubeController) UpdateConfig(c *gin.Context) {
 		return
 	}
 
-	configMap.Data = s.configToMap(&kubeBadgeConfig)
+	// merge, so keys edited outside the dashboard such as custom_resources
+	// are kept
+	for key, value := range s.configToMap(&kubeBadgeConfig) {
+		configMap.Data[key] = value
+	}
 
 	configMap, err = s.KubeHelper.UpdateConfig(configMap)
 	if err != nil {
@@ -300,6 +364,35 @@ func (s *KubeController) ListJobs(c *gin.Context) {
 	c.JSON(http.StatusOK, s.populateKubeBadges(result))
 }
 
+func (s *KubeController) ListCronJobs(c *gin.Context) {
+	namespace := c.Param("namespace")
+	key := fmt.Sprintf("cronjobs_%s", namespace)
+
+	result, ok := s.cache.Get(key)
+	if !ok || c.Query("force") == "true" {
+		cronJobs, err := s.KubeHelper.GetCronJobs(namespace)
+		if err != nil {
+			c.JSON(500, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		var out []model.KubeBadges
+		for _, cronJob := range cronJobs {
+			out = append(out, model.KubeBadges{
+				Kind:  "cronjob",
+				Name:  cronJob.Name,
+				Key:   fmt.Sprintf("/kube/cronjob/%s/%s", namespace, cronJob.Name),
+				Badge: fmt.Sprintf("/badges/kube/cronjob/%s/%s", namespace, cronJob.Name),
+			})
+		}
+		result = out
+		s.cache.Set(key, result, time.Minute*2)
+	}
+
+	c.JSON(http.StatusOK, s.populateKubeBadges(result))
+}
+
 func (s *KubeController) ListPostgresqls(c *gin.Context) {
 	namespace := c.Param("namespace")
 	key := fmt.Sprintf("postgresql_%s", namespace)
@@ -331,6 +424,46 @@ func (s *KubeController) ListPostgresqls(c *gin.Context) {
 	c.JSON(http.StatusOK, s.populateKubeBadges(result))
 }
 
+func (s *KubeController) ListCustomResources(c *gin.Context) {
+	group := c.Param("group")
+	resource := c.Param("resource")
+	namespace := c.Param("namespace")
+	key := fmt.Sprintf("custom_%s_%s_%s", group, resource, namespace)
+
+	result, ok := s.cache.Get(key)
+	if !ok || c.Query("force") == "true" {
+		definition, err := s.CustomResourcesService.GetDefinition(group, resource)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		objs, err := s.KubeHelper.GetResources(definition.GVR(), namespace)
+		if err != nil {
+			c.JSON(500, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		var out []model.KubeBadges
+		for _, obj := range objs {
+			metadata, _ := obj["metadata"].(map[string]interface{})
+			name, _ := metadata["name"].(string)
+			out = append(out, model.KubeBadges{
+				Kind:  "custom",
+				Name:  name,
+				Key:   fmt.Sprintf("/kube/custom/%s/%s/%s/%s", group, resource, namespace, name),
+				Badge: fmt.Sprintf("/badges/kube/custom/%s/%s/%s/%s", group, resource, namespace, name),
+			})
+		}
+		result = out
+		s.cache.Set(key, result, time.Minute*2)
+	}
+
+	c.JSON(http.StatusOK, s.populateKubeBadges(result))
+}
+
 func (s *KubeController) ListKustomizations(c *gin.Context) {
 	namespace := c.Param("namespace")
 	key := fmt.Sprintf("kustomizations_%s", namespace)
@@ -361,3 +494,97 @@ func (s *KubeController) ListKustomizations(c *gin.Context) {
 
 	c.JSON(http.StatusOK, s.populateKubeBadges(result))
 }
+
+// ListOrphans returns the KubeBadges whose resource no longer exists, in the
+// namespaces where the user may list KubeBadges.
+func (s *KubeController) ListOrphans(c *gin.Context) {
+	listable := listableIn(c)
+	result := []model.OrphanedBadge{}
+	for _, orphan := range s.OrphanService.Orphans() {
+		allowed, err := listable(orphan.Namespace)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if allowed {
+			result = append(result, orphan)
+		}
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// GetHistory returns the status changes of a badge key within the window,
+// starting with the state at the beginning of the window, and its uptime.
+func (s *KubeController) GetHistory(c *gin.Context) {
+	key := c.Query("key")
+	if len(key) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "key is required"})
+		return
+	}
+	windowName := c.DefaultQuery("window", defaultUptimeWindow)
+	window, err := service.ParseWindow(windowName)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	now := time.Now()
+	start := now.Add(-window)
+	changes := s.HistoryService.History(key)
+	first := 0
+	for first+1 < len(changes) && !changes[first+1].Time.After(start) {
+		first++
+	}
+	changes = changes[first:]
+
+	result := gin.H{
+		"key":     key,
+		"window":  windowName,
+		"changes": changes,
+		"uptime":  nil,
+	}
+	if uptime, ok := service.Uptime(changes, start, now); ok {
+		result["uptime"] = uptime
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// defaultSignedURLLifetime is used when a sign request has no expires_in.
+const defaultSignedURLLifetime = "30d"
+
+type SignBadgeRequest struct {
+	Key       string `json:"key"`
+	ExpiresIn string `json:"expires_in"`
+}
+
+// SignBadge mints a URL that serves the badge of a key until it expires,
+// whether or not the badge is allowed.
+func (s *KubeController) SignBadge(c *gin.Context) {
+	var req SignBadgeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if len(s.Config.SigningKey) == 0 {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "SIGNING_KEY is not configured"})
+		return
+	}
+	if _, ok := parseBadgeKey(req.Key); !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid key"})
+		return
+	}
+	if len(req.ExpiresIn) == 0 {
+		req.ExpiresIn = defaultSignedURLLifetime
+	}
+	lifetime, err := service.ParseWindow(req.ExpiresIn)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	expires := time.Now().Add(lifetime).Truncate(time.Second)
+	c.JSON(http.StatusOK, gin.H{
+		"url":     "/badges" + req.Key + "?" + signing.Query([]byte(s.Config.SigningKey), req.Key, expires),
+		"expires": expires,
+	})
+}
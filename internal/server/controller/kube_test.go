@@ -1,81 +1,16 @@
 package controller
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"reflect"
+	"strings"
 	"testing"
 
+	"github.com/gin-gonic/gin"
 	"github.com/kubebadges/kubebadges/internal/model"
 )
 
-func TestKubeController_parseKey(t *testing.T) {
-	type args struct {
-		key string
-	}
-	tests := []struct {
-		name             string
-		kubeController   *KubeController
-		args             args
-		wantResourceType string
-		wantNamespace    string
-		wantName         string
-	}{
-		{
-			name:           "node",
-			kubeController: &KubeController{},
-			args: args{
-				key: "/kube/node/minikube",
-			},
-			wantResourceType: "node",
-			wantNamespace:    "",
-			wantName:         "minikube",
-		},
-		{
-			name:           "namespace",
-			kubeController: &KubeController{},
-			args: args{
-				key: "/kube/namespace/default",
-			},
-			wantResourceType: "namespace",
-			wantNamespace:    "",
-			wantName:         "default",
-		},
-		{
-			name:           "deployment",
-			kubeController: &KubeController{},
-			args: args{
-				key: "/kube/deployment/default/nginx",
-			},
-			wantResourceType: "deployment",
-			wantNamespace:    "default",
-			wantName:         "nginx",
-		},
-		{
-			name:           "pod",
-			kubeController: &KubeController{},
-			args: args{
-				key: "/kube/pod/default/nginx-123",
-			},
-			wantResourceType: "pod",
-			wantNamespace:    "default",
-			wantName:         "nginx-123",
-		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			gotResourceType, gotNamespace, gotName := tt.kubeController.parseKey(tt.args.key)
-			if gotResourceType != tt.wantResourceType {
-				t.Errorf("KubeController.parseKey() gotResourceType = %v, want %v", gotResourceType, tt.wantResourceType)
-			}
-			if gotNamespace != tt.wantNamespace {
-				t.Errorf("KubeController.parseKey() gotNamespace = %v, want %v", gotNamespace, tt.wantNamespace)
-			}
-			if gotName != tt.wantName {
-				t.Errorf("KubeController.parseKey() gotName = %v, want %v", gotName, tt.wantName)
-			}
-		})
-	}
-}
-
 func TestKubeController_configToMap(t *testing.T) {
 	type args struct {
 		config *model.KubeBadgesConfig
@@ -150,3 +85,20 @@ func TestKubeController_mapToConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestKubeController_UpdateBadgeInvalidKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/api/badge", (&KubeController{}).UpdateBadge)
+
+	for _, key := range []string{"/kube/custom/g/r", "/kube/deployment", "/", ""} {
+		t.Run(key, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			body := strings.NewReader(`{"key": "` + key + `", "display_name": "x"}`)
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/badge", body))
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("UpdateBadge(%q) status = %d, want %d", key, w.Code, http.StatusBadRequest)
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package controller
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/kubebadges/kubebadges/internal/badges"
+	"github.com/kubebadges/kubebadges/internal/model"
+)
+
+var errUnknownBadgeKey = errors.New("unknown badge key")
+
+// badgeKey is a parsed badge key such as /kube/deployment/shop/api,
+// /kube/custom/cert-manager.io/certificates/shop/tls, /probe/api or
+// /group/checkout.
+type badgeKey struct {
+	kind      string
+	group     string // custom resources only
+	resource  string // custom resources only
+	namespace string
+	name      string
+}
+
+func parseBadgeKey(key string) (badgeKey, bool) {
+	segments := strings.Split(strings.Trim(key, "/"), "/")
+	for _, segment := range segments {
+		if len(segment) == 0 {
+			return badgeKey{}, false
+		}
+	}
+
+	switch {
+	case len(segments) == 2 && (segments[0] == "probe" || segments[0] == "group"):
+		return badgeKey{kind: segments[0], name: segments[1]}, true
+	case len(segments) < 3 || segments[0] != "kube":
+		return badgeKey{}, false
+	}
+
+	switch kind := segments[1]; kind {
+	case "node", "namespace":
+		if len(segments) == 3 {
+			return badgeKey{kind: kind, name: segments[2]}, true
+		}
+	case "summary":
+		if len(segments) == 3 {
+			return badgeKey{kind: kind, namespace: segments[2], name: segments[2]}, true
+		}
+	case "deployment", "statefulset", "daemonset", "pod", "job", "cronjob", "kustomization", "postgresql":
+		if len(segments) == 4 {
+			return badgeKey{kind: kind, namespace: segments[2], name: segments[3]}, true
+		}
+	case "custom":
+		if len(segments) == 6 {
+			return badgeKey{kind: kind, group: segments[2], resource: segments[3], namespace: segments[4], name: segments[5]}, true
+		}
+	}
+	return badgeKey{}, false
+}
+
+// metricKind names the kind in metric labels. Custom resources use the
+// resource.group form of kubectl.
+func (k badgeKey) metricKind() string {
+	if k.kind != "custom" {
+		return k.kind
+	}
+	if k.group == "core" {
+		return k.resource
+	}
+	return k.resource + "." + k.group
+}
+
+// Evaluate computes the badge of a key the same way the badge routes do, for
+// callers that are not serving a request. Query parameters are not
+// available, so CronJob badges use the configured max age.
+func (s *BadgesController) Evaluate(key string) (BadgeMessage, error) {
+	parsed, ok := parseBadgeKey(key)
+	if !ok {
+		return BadgeMessage{}, errUnknownBadgeKey
+	}
+
+	switch parsed.kind {
+	case "node":
+		return s.nodeBadge(parsed.name)
+	case "namespace":
+		return s.namespaceBadge(parsed.name)
+	case "summary":
+		return s.summaryBadge(nil, parsed.name)
+	case "deployment":
+		return s.deploymentBadge(parsed.namespace, parsed.name)
+	case "statefulset":
+		return s.statefulSetBadge(parsed.namespace, parsed.name)
+	case "daemonset":
+		return s.daemonSetBadge(parsed.namespace, parsed.name)
+	case "pod":
+		return s.podBadge(parsed.namespace, parsed.name)
+	case "job":
+		return s.jobBadge(parsed.namespace, parsed.name)
+	case "cronjob":
+		return s.cronJobBadge(parsed.namespace, parsed.name, time.Duration(s.Config.CronJobMaxAge)*time.Second)
+	case "kustomization":
+		return s.kustomizationBadge(parsed.namespace, parsed.name)
+	case "postgresql":
+		return s.postgresqlBadge(parsed.namespace, parsed.name)
+	case "custom":
+		return s.customBadge(parsed.group, parsed.resource, parsed.namespace, parsed.name)
+	case "probe":
+		return s.probeBadge(parsed.name)
+	case "group":
+		return s.groupBadge(parsed.name)
+	}
+	return BadgeMessage{}, errUnknownBadgeKey
+}
+
+// BadgeStatuses evaluates every allowed KubeBadge. A badge whose resource is
+// gone reports the red "badge not found" that its URL serves.
+func (s *BadgesController) BadgeStatuses() []model.BadgeStatus {
+	return s.badgeStatuses(true)
+}
+
+// AllBadgeStatuses evaluates every KubeBadge, allowed or not.
+func (s *BadgesController) AllBadgeStatuses() []model.BadgeStatus {
+	return s.badgeStatuses(false)
+}
+
+func (s *BadgesController) badgeStatuses(allowedOnly bool) []model.BadgeStatus {
+	var statuses []model.BadgeStatus
+	for _, kubeBadge := range s.KubeBadgesService.ListKubeBadges() {
+		if allowedOnly && !kubeBadge.Spec.Allowed {
+			continue
+		}
+		parsed, ok := parseBadgeKey(kubeBadge.Spec.OriginalURL)
+		if !ok {
+			continue
+		}
+
+		status := model.BadgeStatus{
+			Key:       kubeBadge.Spec.OriginalURL,
+			Kind:      parsed.metricKind(),
+			Namespace: parsed.namespace,
+			Name:      parsed.name,
+			Label:     parsed.name,
+			Message:   "badge not found",
+			Color:     badges.Red,
+			Group:     kubeBadge.Spec.StatusGroup,
+			Allowed:   kubeBadge.Spec.Allowed,
+			Notify:    kubeBadge.Spec.Notify,
+		}
+		if len(status.Group) == 0 {
+			status.Group = kubeBadge.Spec.OwnerNamespace
+		}
+		if badgeMessage, err := s.Evaluate(kubeBadge.Spec.OriginalURL); err == nil {
+			status.Label = badgeMessage.Label
+			status.Message = badgeMessage.Message
+			status.Color = badgeMessage.MessageColor
+		} else {
+			status.Error = err.Error()
+		}
+		if len(kubeBadge.Spec.DisplayName) > 0 {
+			status.Label = kubeBadge.Spec.DisplayName
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
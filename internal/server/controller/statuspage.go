@@ -0,0 +1,98 @@
+package controller
+
+import (
+	_ "embed"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kubebadges/kubebadges/internal/badges"
+	"github.com/kubebadges/kubebadges/internal/model"
+)
+
+// statusPageRefresh is the number of seconds between page reloads.
+const statusPageRefresh = 30
+
+var (
+	//go:embed templates/status.html
+	statusPageHTML     string
+	statusPageTemplate = template.Must(template.New("status").Parse(statusPageHTML))
+)
+
+type statusPage struct {
+	Overall  statusPageBadge
+	Sections []statusPageSection
+	Refresh  int
+	Now      time.Time
+}
+
+type statusPageSection struct {
+	Name   string
+	Badges []statusPageBadge
+}
+
+type statusPageBadge struct {
+	Label      string
+	Message    string
+	Hex        string
+	Changed    time.Time
+	ChangedAgo string
+}
+
+// StatusPage renders every allowed badge as an HTML page grouped by status
+// group or owner namespace. It reloads itself and needs no JavaScript.
+func (s *BadgesController) StatusPage(c *gin.Context) {
+	now := time.Now()
+	page := buildStatusPage(s.BadgeStatuses(), s.HistoryService.History, now)
+
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	c.Status(http.StatusOK)
+	if err := statusPageTemplate.Execute(c.Writer, page); err != nil {
+		slog.Error("failed to render status page", "error", err)
+	}
+}
+
+// buildStatusPage sorts the statuses into sections. The last change comes
+// from the badge history, and is left empty when none was recorded.
+func buildStatusPage(statuses []model.BadgeStatus, history func(key string) []model.StatusChange, now time.Time) statusPage {
+	sections := map[string][]statusPageBadge{}
+	worst := badges.Green
+	for _, status := range statuses {
+		badge := statusPageBadge{
+			Label:      status.Label,
+			Message:    status.Message,
+			Hex:        badges.ResolveColor(status.Color, "#9f9f9f"),
+			ChangedAgo: "-",
+		}
+		if changes := history(status.Key); len(changes) > 0 {
+			badge.Changed = changes[len(changes)-1].Time
+			badge.ChangedAgo = formatAge(now.Sub(badge.Changed))
+		}
+		sections[status.Group] = append(sections[status.Group], badge)
+
+		if colorSeverity(status.Color) > colorSeverity(worst) {
+			worst = status.Color
+		}
+	}
+
+	page := statusPage{Refresh: statusPageRefresh, Now: now}
+	for name, sectionBadges := range sections {
+		sort.Slice(sectionBadges, func(i, j int) bool { return sectionBadges[i].Label < sectionBadges[j].Label })
+		page.Sections = append(page.Sections, statusPageSection{Name: name, Badges: sectionBadges})
+	}
+	sort.Slice(page.Sections, func(i, j int) bool { return page.Sections[i].Name < page.Sections[j].Name })
+
+	page.Overall = statusPageBadge{Hex: badges.ResolveColor(worst, "#9f9f9f")}
+	switch colorSeverity(worst) {
+	case 0:
+		page.Overall.Message = "All systems operational"
+	case 3:
+		page.Overall.Message = "Major outage"
+	default:
+		page.Overall.Message = "Degraded performance"
+	}
+	return page
+}
@@ -0,0 +1,51 @@
+package controller
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kubebadges/kubebadges/internal/badges"
+	"github.com/kubebadges/kubebadges/internal/model"
+)
+
+func TestBuildStatusPage(t *testing.T) {
+	now := time.Date(2024, 1, 2, 12, 0, 0, 0, time.UTC)
+	statuses := []model.BadgeStatus{
+		{Key: "/kube/deployment/shop/web", Label: "web", Message: "1/1 Available", Color: badges.Green, Group: "shop"},
+		{Key: "/kube/deployment/shop/api", Label: "api", Message: "0/1 Unavailable", Color: badges.Red, Group: "shop"},
+		{Key: "/probe/docs", Label: "docs", Message: "up 12ms", Color: badges.Green},
+	}
+	history := func(key string) []model.StatusChange {
+		if key == "/kube/deployment/shop/api" {
+			return []model.StatusChange{{Time: now.Add(-3 * time.Hour), Color: badges.Red}}
+		}
+		return nil
+	}
+
+	page := buildStatusPage(statuses, history, now)
+	if page.Overall.Message != "Major outage" {
+		t.Errorf("buildStatusPage() overall = %v, want Major outage", page.Overall.Message)
+	}
+	if len(page.Sections) != 2 || page.Sections[0].Name != "" || page.Sections[1].Name != "shop" {
+		t.Fatalf("buildStatusPage() sections = %v", page.Sections)
+	}
+	shop := page.Sections[1].Badges
+	if len(shop) != 2 || shop[0].Label != "api" || shop[1].Label != "web" {
+		t.Fatalf("buildStatusPage() shop badges = %v", shop)
+	}
+	if shop[0].ChangedAgo != "3h ago" || shop[1].ChangedAgo != "-" {
+		t.Errorf("buildStatusPage() changed = %v %v", shop[0].ChangedAgo, shop[1].ChangedAgo)
+	}
+
+	var out bytes.Buffer
+	if err := statusPageTemplate.Execute(&out, page); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	for _, want := range []string{"Major outage", "0/1 Unavailable", "General", `content="30"`} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("rendered page does not contain %q", want)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package controller
+
+import (
+	"io"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// streamHeartbeat keeps idle connections open through proxies.
+const streamHeartbeat = 15 * time.Second
+
+// Stream pushes badge states as Server-Sent Events named "badge". A client
+// first receives the current state of every badge, then each change, and a
+// "badge-removed" event with the key of a badge that is gone. With
+// allowedOnly, badges that are not allowed are never sent, and a badge that
+// stops being allowed is sent as removed.
+func (s *BadgesController) Stream(allowedOnly bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		subscription := s.StreamService.Subscribe(allowedOnly)
+		defer s.StreamService.Unsubscribe(subscription)
+
+		c.Header("Content-Type", "text/event-stream")
+		c.Header("Cache-Control", "no-cache")
+		c.Header("X-Accel-Buffering", "no")
+
+		heartbeat := time.NewTicker(streamHeartbeat)
+		defer heartbeat.Stop()
+
+		c.Stream(func(w io.Writer) bool {
+			select {
+			case event, ok := <-subscription.Events:
+				if !ok {
+					return false
+				}
+				if event.Removed {
+					c.SSEvent("badge-removed", gin.H{"key": event.Key})
+				} else {
+					c.SSEvent("badge", event)
+				}
+				return true
+			case <-heartbeat.C:
+				_, err := io.WriteString(w, ": heartbeat\n\n")
+				return err == nil
+			case <-c.Request.Context().Done():
+				return false
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package controller
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kubebadges/kubebadges/internal/badges"
+	"github.com/kubebadges/kubebadges/internal/k8s"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// member is the status of one object rolled up into an aggregate badge.
+type member struct {
+	kind    string
+	name    string
+	message string
+	color   string
+}
+
+// colorSeverity ranks the colors of the per-kind rules. Zero is healthy,
+// which includes the blue and grey used for scaled down or unknown states.
+func colorSeverity(color string) int {
+	switch color {
+	case badges.Red:
+		return 3
+	case badges.Orange:
+		return 2
+	case badges.Yellow:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// workloadMembers evaluates the workloads with the per-kind rules, skipping
+// those matched by exclude and, when kinds is not empty, those of other
+// kinds. Pods managed by a controller are left to their owner so that no
+// workload is counted twice, unless pods are asked for explicitly.
+func workloadMembers(workloads *k8s.Workloads, exclude labels.Selector, kinds []string) []member {
+	var members []member
+	add := func(kind string, object metav1.Object, status func() (string, string)) {
+		if (len(kinds) > 0 && !slices.Contains(kinds, kind)) || exclude.Matches(labels.Set(object.GetLabels())) {
+			return
+		}
+		message, color := status()
+		members = append(members, member{kind: kind, name: object.GetName(), message: message, color: color})
+	}
+
+	for _, deployment := range workloads.Deployments {
+		add("deployment", deployment, func() (string, string) { return deploymentStatus(deployment) })
+	}
+	for _, statefulSet := range workloads.StatefulSets {
+		add("statefulset", statefulSet, func() (string, string) { return statefulSetStatus(statefulSet) })
+	}
+	for _, daemonSet := range workloads.DaemonSets {
+		add("daemonset", daemonSet, func() (string, string) { return daemonSetStatus(daemonSet) })
+	}
+	for _, job := range workloads.Jobs {
+		add("job", job, func() (string, string) { return jobStatus(job) })
+	}
+	for _, pod := range workloads.Pods {
+		if metav1.GetControllerOf(pod) != nil && !slices.Contains(kinds, "pod") {
+			continue
+		}
+		add("pod", pod, func() (string, string) { return podStatus(pod) })
+	}
+	return members
+}
+
+const (
+	aggregationWorst    = "worst"
+	aggregationMajority = "majority"
+	aggregationAllGreen = "all-green"
+)
+
+// aggregateStatus reports how many members are healthy. The color is the
+// worst member color, the most common one with ties going to the worse
+// color, or green only while every member is healthy.
+func aggregateStatus(members []member, aggregation string) (string, string) {
+	if len(members) == 0 {
+		return "no workloads", badges.LightGrey
+	}
+
+	healthy := 0
+	worst := badges.Green
+	counts := map[string]int{}
+	for _, m := range members {
+		if colorSeverity(m.color) == 0 {
+			healthy++
+		} else if colorSeverity(m.color) > colorSeverity(worst) {
+			worst = m.color
+		}
+		counts[m.color]++
+	}
+	message := fmt.Sprintf("%d/%d healthy", healthy, len(members))
+
+	switch aggregation {
+	case aggregationMajority:
+		var majority string
+		for _, m := range members {
+			if counts[m.color] > counts[majority] ||
+				(counts[m.color] == counts[majority] && colorSeverity(m.color) > colorSeverity(majority)) {
+				majority = m.color
+			}
+		}
+		return message, majority
+	case aggregationAllGreen:
+		if healthy == len(members) {
+			return message, badges.Green
+		}
+		return message, badges.Red
+	default:
+		return message, worst
+	}
+}
+
+// Summary badge
+func (s *BadgesController) Summary(c *gin.Context) {
+	s.serve(c, func() (BadgeMessage, error) {
+		return s.summaryBadge(c, c.Param("namespace"))
+	})
+}
+
+// summaryBadge aggregates the workloads of a namespace. The include and
+// exclude selectors come from the summary section of its KubeBadge.
+func (s *BadgesController) summaryBadge(c *gin.Context, namespace string) (BadgeMessage, error) {
+	key := fmt.Sprintf("/kube/summary/%s", namespace)
+
+	include, exclude := labels.Everything(), labels.Nothing()
+	if kubeBadge, err := s.kubeBadge(c, key); err == nil {
+		summary := kubeBadge.Spec.Summary
+		if len(summary.Include) > 0 {
+			if include, err = labels.Parse(summary.Include); err != nil {
+				return BadgeMessage{}, err
+			}
+		}
+		if len(summary.Exclude) > 0 {
+			if exclude, err = labels.Parse(summary.Exclude); err != nil {
+				return BadgeMessage{}, err
+			}
+		}
+	}
+
+	workloads, err := s.KubeHelper.GetWorkloads(namespace, include)
+	if err != nil {
+		return BadgeMessage{}, err
+	}
+
+	badgeMessage := BadgeMessage{
+		Key:   key,
+		Label: namespace,
+	}
+	badgeMessage.Message, badgeMessage.MessageColor = aggregateStatus(workloadMembers(workloads, exclude, nil), aggregationWorst)
+
+	return badgeMessage, nil
+}
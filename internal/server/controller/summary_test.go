@@ -0,0 +1,110 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/kubebadges/kubebadges/internal/badges"
+	"github.com/kubebadges/kubebadges/internal/k8s"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+func TestAggregateStatus(t *testing.T) {
+	mixed := []member{{color: badges.Red}, {color: badges.Green}, {color: badges.Green}}
+	tests := []struct {
+		name        string
+		members     []member
+		aggregation string
+		wantMessage string
+		wantColor   string
+	}{
+		{"empty", nil, aggregationWorst, "no workloads", badges.LightGrey},
+		{"all healthy", []member{{color: badges.Green}, {color: badges.Blue}}, aggregationWorst, "2/2 healthy", badges.Green},
+		{"degraded", []member{{color: badges.Green}, {color: badges.Yellow}}, aggregationWorst, "1/2 healthy", badges.Yellow},
+		{"worst wins", []member{{color: badges.Red}, {color: badges.Yellow}, {color: badges.Green}}, aggregationWorst, "1/3 healthy", badges.Red},
+		{"default is worst", mixed, "", "2/3 healthy", badges.Red},
+		{"majority", mixed, aggregationMajority, "2/3 healthy", badges.Green},
+		{"majority tie", []member{{color: badges.Green}, {color: badges.Yellow}}, aggregationMajority, "1/2 healthy", badges.Yellow},
+		{"all green", []member{{color: badges.Green}, {color: badges.Green}}, aggregationAllGreen, "2/2 healthy", badges.Green},
+		{"not all green", []member{{color: badges.Green}, {color: badges.Yellow}}, aggregationAllGreen, "1/2 healthy", badges.Red},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotMessage, gotColor := aggregateStatus(tt.members, tt.aggregation)
+			if gotMessage != tt.wantMessage {
+				t.Errorf("aggregateStatus() gotMessage = %v, want %v", gotMessage, tt.wantMessage)
+			}
+			if gotColor != tt.wantColor {
+				t.Errorf("aggregateStatus() gotColor = %v, want %v", gotColor, tt.wantColor)
+			}
+		})
+	}
+}
+
+func TestWorkloadMembers(t *testing.T) {
+	isController := true
+	workloads := &k8s.Workloads{
+		StatefulSets: []*appsv1.StatefulSet{{
+			ObjectMeta: metav1.ObjectMeta{Name: "db"},
+			Status:     appsv1.StatefulSetStatus{ReadyReplicas: 1, UpdatedReplicas: 1},
+		}},
+		Jobs: []*batchv1.Job{{
+			ObjectMeta: metav1.ObjectMeta{Name: "migrate", Labels: map[string]string{"tier": "batch"}},
+			Status:     batchv1.JobStatus{Failed: 1},
+		}},
+		Pods: []*corev1.Pod{
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "debug"},
+				Status:     corev1.PodStatus{Phase: corev1.PodPending},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:            "db-0",
+					OwnerReferences: []metav1.OwnerReference{{Kind: "StatefulSet", Name: "db", Controller: &isController}},
+				},
+				Status: corev1.PodStatus{Phase: corev1.PodFailed},
+			},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		exclude string
+		kinds   []string
+		want    []member
+	}{
+		{"all", "", nil, []member{
+			{"statefulset", "db", "1/1 Ready", badges.Green},
+			{"job", "migrate", "Failed", badges.Red},
+			{"pod", "debug", "Pending", badges.Yellow},
+		}},
+		{"excluded", "tier=batch", nil, []member{
+			{"statefulset", "db", "1/1 Ready", badges.Green},
+			{"pod", "debug", "Pending", badges.Yellow},
+		}},
+		{"pods only", "", []string{"pod"}, []member{
+			{"pod", "debug", "Pending", badges.Yellow},
+			{"pod", "db-0", "Failed", badges.Red},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exclude := labels.Nothing()
+			if len(tt.exclude) > 0 {
+				exclude, _ = labels.Parse(tt.exclude)
+			}
+			got := workloadMembers(workloads, exclude, tt.kinds)
+			if len(got) != len(tt.want) {
+				t.Fatalf("workloadMembers() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("workloadMembers()[%d] = %v, want %v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,333 @@
+package controller
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kubebadges/kubebadges/internal/auth"
+	"github.com/kubebadges/kubebadges/internal/config"
+	"github.com/kubebadges/kubebadges/internal/server/middleware"
+	"github.com/kubebadges/kubebadges/internal/service"
+	v1 "github.com/kubebadges/kubebadges/pkg/apis/kubebadges/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	importModeMerge   = "merge"
+	importModeReplace = "replace"
+)
+
+// importMaxBytes bounds the size of an imported document.
+const importMaxBytes = 8 << 20
+
+// Import actions reported per badge.
+const (
+	importCreated   = "created"
+	importUpdated   = "updated"
+	importUnchanged = "unchanged"
+	importDeleted   = "deleted"
+	importInvalid   = "invalid"
+	importFailed    = "failed"
+)
+
+// BadgeExport is the document served by GET /api/export and accepted by
+// POST /api/import.
+type BadgeExport struct {
+	Badges []ExportedBadge   `json:"badges"`
+	Config map[string]string `json:"config,omitempty"`
+}
+
+type ExportedBadge struct {
+	Namespace string           `json:"namespace,omitempty"`
+	Name      string           `json:"name,omitempty"`
+	Spec      v1.KubeBadgeSpec `json:"spec"`
+}
+
+type ImportResult struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Key       string `json:"key"`
+	Action    string `json:"action"`
+	Error     string `json:"error,omitempty"`
+}
+
+// importStep is a planned change of one KubeBadge. kubeBadge is the object
+// to create or update, or the one to delete.
+type importStep struct {
+	result    ImportResult
+	kubeBadge *v1.KubeBadge
+}
+
+// Export returns the KubeBadges the user may list and the kubebadge-config
+// ConfigMap, as JSON or, with format=yaml, as YAML. KubeBadges owned by an
+// annotated object are left out, they come back with the annotations.
+func (s *KubeController) Export(c *gin.Context) {
+	configMap, err := s.KubeHelper.GetOrCreateConfig()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	kubeBadges, ok := readableBadges(c, s.KubeBadgesService.ListAllKubeBadges())
+	if !ok {
+		return
+	}
+	document := BadgeExport{Badges: []ExportedBadge{}, Config: configMap.Data}
+	for _, kubeBadge := range kubeBadges {
+		if metav1.GetControllerOf(kubeBadge) != nil {
+			continue
+		}
+		document.Badges = append(document.Badges, ExportedBadge{
+			Namespace: kubeBadge.Namespace,
+			Name:      kubeBadge.Name,
+			Spec:      kubeBadge.Spec,
+		})
+	}
+	sort.Slice(document.Badges, func(i, j int) bool {
+		if document.Badges[i].Namespace != document.Badges[j].Namespace {
+			return document.Badges[i].Namespace < document.Badges[j].Namespace
+		}
+		return document.Badges[i].Name < document.Badges[j].Name
+	})
+
+	if c.Query("format") == "yaml" {
+		data, err := yaml.Marshal(document)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.Data(http.StatusOK, "application/yaml", data)
+		return
+	}
+	c.JSON(http.StatusOK, document)
+}
+
+// Import applies an exported document, in JSON or YAML. In merge mode, the
+// default, badges are created or updated and config keys are set. In
+// replace mode, badges missing from the document are deleted in the
+// namespaces the document names and the config is replaced as a whole.
+// With dry_run=true nothing is written, but the same permissions are
+// required.
+func (s *KubeController) Import(c *gin.Context) {
+	mode := c.DefaultQuery("mode", importModeMerge)
+	if mode != importModeMerge && mode != importModeReplace {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "mode must be merge or replace"})
+		return
+	}
+	dryRun, _ := strconv.ParseBool(c.Query("dry_run"))
+
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, importMaxBytes))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var document BadgeExport
+	if err := yaml.Unmarshal(body, &document); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	steps := planImport(document, s.KubeBadgesService.ListAllKubeBadges(), mode, s.KubeHelper.GenerateKubeName)
+	if !s.authorizeImport(c, document, steps) {
+		return
+	}
+
+	results := make([]ImportResult, 0, len(steps))
+	for _, step := range steps {
+		if !dryRun {
+			s.applyImportStep(&step)
+		}
+		results = append(results, step.result)
+	}
+
+	configAction := importUnchanged
+	if document.Config != nil {
+		configAction, err = s.importConfig(document.Config, mode, dryRun)
+		if err != nil {
+			configAction = importFailed
+		}
+	}
+
+	response := gin.H{"mode": mode, "dry_run": dryRun, "badges": results, "config": configAction}
+	if err != nil {
+		response["config_error"] = err.Error()
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// authorizeImport checks every verb the planned steps need in the namespace
+// of their badge, and configure for the config of the document.
+func (s *KubeController) authorizeImport(c *gin.Context, document BadgeExport, steps []importStep) bool {
+	verbs := map[string]map[string]bool{}
+	need := func(namespace, verb string) {
+		if verbs[namespace] == nil {
+			verbs[namespace] = map[string]bool{}
+		}
+		verbs[namespace][verb] = true
+	}
+	for _, step := range steps {
+		switch step.result.Action {
+		case importDeleted:
+			need(step.kubeBadge.Namespace, auth.VerbDelete)
+		case importCreated, importUpdated:
+			need(step.kubeBadge.Namespace, auth.VerbUpdate)
+			if step.kubeBadge.Spec.Allowed {
+				need(step.kubeBadge.Namespace, auth.VerbPublish)
+			}
+		}
+	}
+	if document.Config != nil {
+		need(config.KubeBadgeNamespace, auth.VerbConfigure)
+	}
+
+	namespaces := make([]string, 0, len(verbs))
+	for namespace := range verbs {
+		namespaces = append(namespaces, namespace)
+	}
+	sort.Strings(namespaces)
+	for _, namespace := range namespaces {
+		for _, verb := range []string{auth.VerbUpdate, auth.VerbPublish, auth.VerbDelete, auth.VerbConfigure} {
+			if verbs[namespace][verb] && !middleware.AuthorizeIn(c, namespace, verb) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (s *KubeController) applyImportStep(step *importStep) {
+	var err error
+	switch step.result.Action {
+	case importCreated:
+		_, err = s.KubeHelper.CreateKubeBadgeIn(step.kubeBadge.Namespace, step.kubeBadge.Spec)
+	case importUpdated:
+		_, err = s.KubeHelper.UpdateKubeBadge(step.kubeBadge)
+	case importDeleted:
+		err = s.KubeHelper.DeleteKubeBadgeAt(step.kubeBadge.Namespace, step.kubeBadge.Name, step.kubeBadge.ResourceVersion)
+	}
+	if err != nil {
+		step.result.Action = importFailed
+		step.result.Error = err.Error()
+	}
+}
+
+func (s *KubeController) importConfig(data map[string]string, mode string, dryRun bool) (string, error) {
+	configMap, err := s.KubeHelper.GetOrCreateConfig()
+	if err != nil {
+		return "", err
+	}
+	merged := map[string]string{}
+	if mode == importModeMerge {
+		for key, value := range configMap.Data {
+			merged[key] = value
+		}
+	}
+	for key, value := range data {
+		merged[key] = value
+	}
+	if reflect.DeepEqual(merged, configMap.Data) || (len(merged) == 0 && len(configMap.Data) == 0) {
+		return importUnchanged, nil
+	}
+	if !dryRun {
+		configMap.Data = merged
+		if _, err := s.KubeHelper.UpdateConfig(configMap); err != nil {
+			return "", err
+		}
+	}
+	return importUpdated, nil
+}
+
+// planImport decides what importing a document does to the existing
+// KubeBadges. Badges are named after their key, so the names in the
+// document are not used. KubeBadges owned by an annotated object, and
+// badges a namespace cannot own, are never touched. Replace mode only
+// deletes in the namespaces of the document, so that importing the badges
+// of one team leaves those of other teams alone.
+func planImport(document BadgeExport, existing []*v1.KubeBadge, mode string, generateName func(string) string) []importStep {
+	current := map[string]*v1.KubeBadge{}
+	for _, kubeBadge := range existing {
+		current[kubeBadge.Namespace+"/"+kubeBadge.Name] = kubeBadge
+	}
+
+	var steps []importStep
+	imported := map[string]bool{}
+	namespaces := map[string]bool{}
+	for _, badge := range document.Badges {
+		namespace := badge.Namespace
+		if len(namespace) == 0 {
+			namespace = config.KubeBadgeNamespace
+		}
+		namespaces[namespace] = true
+		step := importStep{result: ImportResult{Namespace: namespace, Key: badge.Spec.OriginalURL}}
+
+		parsed, ok := parseBadgeKey(badge.Spec.OriginalURL)
+		if !ok {
+			step.result.Name = badge.Name
+			step.result.Action = importInvalid
+			step.result.Error = "spec.originalURL is not a valid badge key"
+			steps = append(steps, step)
+			continue
+		}
+		spec := badge.Spec
+		if len(spec.Type) == 0 {
+			spec.Type = parsed.kind
+		}
+		name := generateName(spec.OriginalURL)
+		step.result.Name = name
+		if !service.NamespaceOwns(namespace, spec) {
+			step.result.Action = importInvalid
+			step.result.Error = errForeignBadge(namespace).Error()
+			steps = append(steps, step)
+			continue
+		}
+		if imported[namespace+"/"+name] {
+			step.result.Action = importInvalid
+			step.result.Error = "duplicate badge in document"
+			steps = append(steps, step)
+			continue
+		}
+		imported[namespace+"/"+name] = true
+
+		kubeBadge, found := current[namespace+"/"+name]
+		switch {
+		case !found:
+			step.result.Action = importCreated
+			step.kubeBadge = &v1.KubeBadge{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name}, Spec: spec}
+		case metav1.GetControllerOf(kubeBadge) != nil:
+			step.result.Action = importInvalid
+			step.result.Error = "badge is managed by annotations"
+		case reflect.DeepEqual(kubeBadge.Spec, spec):
+			step.result.Action = importUnchanged
+		default:
+			step.result.Action = importUpdated
+			step.kubeBadge = kubeBadge.DeepCopy()
+			step.kubeBadge.Spec = spec
+		}
+		steps = append(steps, step)
+	}
+
+	if mode == importModeReplace {
+		for _, kubeBadge := range existing {
+			key := kubeBadge.Namespace + "/" + kubeBadge.Name
+			if imported[key] || !namespaces[kubeBadge.Namespace] || metav1.GetControllerOf(kubeBadge) != nil {
+				continue
+			}
+			steps = append(steps, importStep{
+				result:    ImportResult{Namespace: kubeBadge.Namespace, Name: kubeBadge.Name, Key: kubeBadge.Spec.OriginalURL, Action: importDeleted},
+				kubeBadge: kubeBadge,
+			})
+		}
+	}
+	return steps
+}
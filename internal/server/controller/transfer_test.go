@@ -0,0 +1,151 @@
+package controller
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kubebadges/kubebadges/internal/auth"
+	"github.com/kubebadges/kubebadges/internal/server/middleware"
+	v1 "github.com/kubebadges/kubebadges/pkg/apis/kubebadges/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPlanImport(t *testing.T) {
+	generateName := func(key string) string {
+		return strings.ReplaceAll(strings.Trim(key, "/"), "/", "-")
+	}
+	newKubeBadge := func(namespace, key string, allowed bool) *v1.KubeBadge {
+		return &v1.KubeBadge{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: generateName(key)},
+			Spec:       v1.KubeBadgeSpec{Type: "deployment", OriginalURL: key, Allowed: allowed},
+		}
+	}
+	annotated := newKubeBadge("shop", "/kube/deployment/shop/cart", true)
+	controller := true
+	annotated.OwnerReferences = []metav1.OwnerReference{{Kind: "Deployment", Name: "cart", Controller: &controller}}
+	existing := []*v1.KubeBadge{
+		newKubeBadge("kubebadges", "/kube/deployment/shop/web", true),
+		newKubeBadge("kubebadges", "/kube/deployment/shop/api", false),
+		newKubeBadge("kubebadges", "/kube/deployment/shop/old", true),
+		annotated,
+		newKubeBadge("billing", "/kube/deployment/billing/api", true),
+	}
+	exported := func(namespace, key string, allowed bool) ExportedBadge {
+		return ExportedBadge{Namespace: namespace, Spec: v1.KubeBadgeSpec{OriginalURL: key, Allowed: allowed}}
+	}
+	document := BadgeExport{Badges: []ExportedBadge{
+		exported("", "/kube/deployment/shop/web", true),
+		exported("", "/kube/deployment/shop/api", true),
+		exported("shop", "/kube/deployment/shop/new", true),
+		exported("", "/kube/deployment/shop", true),
+		exported("", "/kube/deployment/shop/web", false),
+		exported("shop", "/kube/deployment/shop/cart", false),
+		exported("shop", "/kube/deployment/billing/ledger", true),
+	}}
+	document.Badges[0].Spec.Type = "deployment"
+
+	tests := []struct {
+		name string
+		mode string
+		want []string
+	}{
+		{"merge", importModeMerge, []string{
+			"kubebadges/kube-deployment-shop-web unchanged",
+			"kubebadges/kube-deployment-shop-api updated",
+			"shop/kube-deployment-shop-new created",
+			"kubebadges/ invalid",
+			"kubebadges/kube-deployment-shop-web invalid",
+			"shop/kube-deployment-shop-cart invalid",
+			"shop/kube-deployment-billing-ledger invalid",
+		}},
+		{"replace deletes unlisted, unmanaged badges of the namespaces in the document", importModeReplace, []string{
+			"kubebadges/kube-deployment-shop-web unchanged",
+			"kubebadges/kube-deployment-shop-api updated",
+			"shop/kube-deployment-shop-new created",
+			"kubebadges/ invalid",
+			"kubebadges/kube-deployment-shop-web invalid",
+			"shop/kube-deployment-shop-cart invalid",
+			"shop/kube-deployment-billing-ledger invalid",
+			"kubebadges/kube-deployment-shop-old deleted",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := []string{}
+			for _, step := range planImport(document, existing, tt.mode, generateName) {
+				got = append(got, step.result.Namespace+"/"+step.result.Name+" "+step.result.Action)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("planImport() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	steps := planImport(document, existing, importModeMerge, generateName)
+	if steps[1].kubeBadge.ResourceVersion != existing[1].ResourceVersion || !steps[1].kubeBadge.Spec.Allowed || existing[1].Spec.Allowed {
+		t.Errorf("update must copy the existing KubeBadge, got %+v", steps[1].kubeBadge)
+	}
+	if steps[2].kubeBadge.Spec.Type != "deployment" {
+		t.Errorf("created spec type = %q, want it taken from the key", steps[2].kubeBadge.Spec.Type)
+	}
+}
+
+func TestImportTooLarge(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/api/import", (&KubeController{}).Import)
+
+	w := httptest.NewRecorder()
+	body := strings.NewReader("badges: []\n" + strings.Repeat("#", importMaxBytes))
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/import?dry_run=true", body))
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Import() status = %d, want %d", w.Code, http.StatusRequestEntityTooLarge)
+	}
+}
+
+func TestAuthorizeImport(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	step := func(namespace, action string, allowed bool) importStep {
+		return importStep{
+			result:    ImportResult{Namespace: namespace, Action: action},
+			kubeBadge: &v1.KubeBadge{ObjectMeta: metav1.ObjectMeta{Namespace: namespace}, Spec: v1.KubeBadgeSpec{Allowed: allowed}},
+		}
+	}
+	authorizer := namespaceAuthorizer{"shop update": true, "shop publish": true, "shop delete": true}
+
+	tests := []struct {
+		name     string
+		document BadgeExport
+		steps    []importStep
+		want     int
+	}{
+		{"own namespace", BadgeExport{}, []importStep{step("shop", importCreated, true), step("shop", importDeleted, false)}, http.StatusOK},
+		{"delete in another namespace", BadgeExport{}, []importStep{step("shop", importUpdated, false), step("billing", importDeleted, false)}, http.StatusForbidden},
+		{"publish in another namespace", BadgeExport{}, []importStep{step("billing", importCreated, true)}, http.StatusForbidden},
+		{"config", BadgeExport{Config: map[string]string{}}, []importStep{step("shop", importCreated, false)}, http.StatusForbidden},
+		{"nothing to write", BadgeExport{}, []importStep{{result: ImportResult{Namespace: "billing", Action: importInvalid}}}, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			api := router.Group("/api", middleware.AuthMiddleware(auth.StaticTokens{"team-token": {Name: "team"}}, authorizer))
+			api.POST("/import", func(c *gin.Context) {
+				if (&KubeController{}).authorizeImport(c, tt.document, tt.steps) {
+					c.Status(http.StatusOK)
+				}
+			})
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/api/import", nil)
+			req.Header.Set("Authorization", "Bearer team-token")
+			router.ServeHTTP(w, req)
+			if w.Code != tt.want {
+				t.Errorf("authorizeImport() status = %d, want %d", w.Code, tt.want)
+			}
+		})
+	}
+}
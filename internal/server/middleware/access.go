@@ -1,10 +1,13 @@
 package middleware
 
 import (
+	"context"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/kubebadges/kubebadges/internal/signing"
 	v1 "github.com/kubebadges/kubebadges/pkg/apis/kubebadges/v1"
 )
 
@@ -31,26 +34,61 @@ var unauthorizedSvg = `<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http
 </svg>
 `
 
+const (
+	badgesPathPrefix = "/badges"
+	aliasPathPrefix  = "/b/"
+	uptimePathSuffix = "/uptime"
+	statusPagePath   = "/status"
+	streamPath       = "/events"
+)
+
 type KubeBadgeService interface {
 	GetKubeBadge(key string, b bool) (*v1.KubeBadge, error)
+	GetKubeBadgeByAlias(aliasURL string) (*v1.KubeBadge, error)
 }
 
-func BadgeApiAccessMiddleware(kubeService KubeBadgeService) gin.HandlerFunc {
+// BadgeApiAccessMiddleware serves a badge when its KubeBadge is allowed, or
+// when the URL carries a valid signature made with signingKey.
+func BadgeApiAccessMiddleware(kubeService KubeBadgeService, signingKey []byte) gin.HandlerFunc {
 	return func(c *gin.Context) {
 
 		c.Header("X-App-Name", "KubeBadge")
 		c.Header("Cache-Control", "private, max-age=0, no-cache")
 
-		if !strings.HasPrefix(c.Request.URL.Path, "/badges") {
+		var kubeBadge *v1.KubeBadge
+		var key string
+		var err error
+		switch path := c.Request.URL.Path; {
+		case path == statusPagePath || path == streamPath:
+			// the status page and the stream only show allowed badges
+			c.Next()
+			return
+		case strings.HasPrefix(path, badgesPathPrefix):
+			key = strings.TrimPrefix(path, badgesPathPrefix)
+			// an uptime badge follows the access rule of the badge it measures
+			if strings.HasSuffix(c.FullPath(), uptimePathSuffix) {
+				key = strings.TrimSuffix(key, uptimePathSuffix)
+			}
+			if resolved, ok := KubeBadgeOf(c, key); ok {
+				kubeBadge = resolved
+			} else {
+				kubeBadge, err = kubeService.GetKubeBadge(key, false)
+			}
+		case strings.HasPrefix(path, aliasPathPrefix):
+			kubeBadge, err = kubeService.GetKubeBadgeByAlias(strings.TrimPrefix(path, aliasPathPrefix))
+			if err == nil {
+				key = kubeBadge.Spec.OriginalURL
+				WithKubeBadge(c, kubeBadge)
+			}
+		default:
 			c.Header("Content-Type", "image/svg+xml")
 			c.String(http.StatusOK, unauthorizedSvg)
 			c.Abort()
 			return
 		}
 
-		key := strings.TrimPrefix(c.Request.URL.Path, "/badges")
-		kubeBadge, err := kubeService.GetKubeBadge(key, false)
-		if err != nil || !kubeBadge.Spec.Allowed {
+		allowed := err == nil && kubeBadge.Spec.Allowed
+		if !allowed && !signing.Verify(signingKey, key, c.Query(signing.SignatureParam), c.Query(signing.ExpiresParam), time.Now()) {
 			c.Header("Content-Type", "image/svg+xml")
 			c.String(http.StatusOK, unauthorizedSvg)
 			c.Abort()
@@ -60,3 +98,33 @@ func BadgeApiAccessMiddleware(kubeService KubeBadgeService) gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+type kubeBadgeContextKey struct{}
+
+// WithKubeBadge records on the request the KubeBadge it was resolved to,
+// such as the holder of an alias. An alias is served by dispatching its
+// badge URL again, and the badge key alone may name the KubeBadge of
+// another namespace. The request context survives gin's HandleContext,
+// unlike the keys of gin.Context.
+func WithKubeBadge(c *gin.Context, kubeBadge *v1.KubeBadge) {
+	c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), kubeBadgeContextKey{}, kubeBadge))
+}
+
+// ResolvedKubeBadge returns the KubeBadge recorded by WithKubeBadge.
+func ResolvedKubeBadge(c *gin.Context) (*v1.KubeBadge, bool) {
+	if c == nil || c.Request == nil {
+		return nil, false
+	}
+	kubeBadge, ok := c.Request.Context().Value(kubeBadgeContextKey{}).(*v1.KubeBadge)
+	return kubeBadge, ok
+}
+
+// KubeBadgeOf returns the KubeBadge recorded by WithKubeBadge if it is the
+// one of key.
+func KubeBadgeOf(c *gin.Context, key string) (*v1.KubeBadge, bool) {
+	kubeBadge, ok := ResolvedKubeBadge(c)
+	if !ok || strings.Trim(kubeBadge.Spec.OriginalURL, "/") != strings.Trim(key, "/") {
+		return nil, false
+	}
+	return kubeBadge, true
+}
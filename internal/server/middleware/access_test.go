@@ -1,13 +1,17 @@
 package middleware
 
 import (
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/kubebadges/kubebadges/internal/signing"
 	v1 "github.com/kubebadges/kubebadges/pkg/apis/kubebadges/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 type MockKubeBadgesService struct{}
@@ -20,13 +24,26 @@ func (s *MockKubeBadgesService) GetKubeBadge(key string, b bool) (*v1.KubeBadge,
 	return &v1.KubeBadge{Spec: v1.KubeBadgeSpec{Allowed: false}}, nil
 }
 
+func (s *MockKubeBadgesService) GetKubeBadgeByAlias(aliasURL string) (*v1.KubeBadge, error) {
+	if aliasURL == "authorized" {
+		return &v1.KubeBadge{Spec: v1.KubeBadgeSpec{Allowed: true}}, nil
+	}
+	if aliasURL == "team" {
+		return &v1.KubeBadge{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "kube-deployment-shop-api"},
+			Spec:       v1.KubeBadgeSpec{OriginalURL: "/kube/deployment/shop/api", Allowed: true},
+		}, nil
+	}
+	return nil, errors.New("not found")
+}
+
 func TestBadgeApiAccessMiddleware(t *testing.T) {
 	kubeBadgeService := &MockKubeBadgesService{}
 	gin.SetMode(gin.TestMode)
 
 	t.Run("unauthorized path", func(t *testing.T) {
 		router := gin.New()
-		router.Use(BadgeApiAccessMiddleware(kubeBadgeService))
+		router.Use(BadgeApiAccessMiddleware(kubeBadgeService, nil))
 		router.GET("/badges", func(c *gin.Context) {
 			c.String(200, "ok")
 		})
@@ -46,7 +63,7 @@ func TestBadgeApiAccessMiddleware(t *testing.T) {
 
 	t.Run("unauthorized badge", func(t *testing.T) {
 		router := gin.New()
-		router.Use(BadgeApiAccessMiddleware(kubeBadgeService))
+		router.Use(BadgeApiAccessMiddleware(kubeBadgeService, nil))
 		router.GET("/badges/some", func(c *gin.Context) {})
 
 		w := httptest.NewRecorder()
@@ -64,7 +81,7 @@ func TestBadgeApiAccessMiddleware(t *testing.T) {
 
 	t.Run("authorized badge", func(t *testing.T) {
 		router := gin.New()
-		router.Use(BadgeApiAccessMiddleware(kubeBadgeService))
+		router.Use(BadgeApiAccessMiddleware(kubeBadgeService, nil))
 		router.GET("/badges/authorized", func(c *gin.Context) {})
 
 		w := httptest.NewRecorder()
@@ -75,4 +92,115 @@ func TestBadgeApiAccessMiddleware(t *testing.T) {
 			t.Fatalf("expected status code %d, but got %d", http.StatusOK, w.Code)
 		}
 	})
+
+	t.Run("authorized alias", func(t *testing.T) {
+		router := gin.New()
+		router.Use(BadgeApiAccessMiddleware(kubeBadgeService, nil))
+		router.GET("/b/*alias", func(c *gin.Context) {})
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/b/authorized", nil)
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected status code %d, but got %d", http.StatusOK, w.Code)
+		}
+		if strings.Contains(w.Body.String(), unauthorizedSvg) {
+			t.Fatalf("expected response body not to contain %s", unauthorizedSvg)
+		}
+	})
+
+	t.Run("alias dispatched to its own KubeBadge", func(t *testing.T) {
+		// the key of the alias also has a KubeBadge in the platform
+		// namespace, which is not allowed
+		router := gin.New()
+		router.Use(BadgeApiAccessMiddleware(kubeBadgeService, nil))
+		router.GET("/b/*alias", func(c *gin.Context) {
+			kubeBadge, _ := ResolvedKubeBadge(c)
+			c.Request.URL.Path = "/badges" + kubeBadge.Spec.OriginalURL
+			router.HandleContext(c)
+		})
+		router.GET("/badges/kube/deployment/:namespace/:name", func(c *gin.Context) {
+			kubeBadge, _ := KubeBadgeOf(c, "/kube/deployment/shop/api")
+			c.String(http.StatusOK, kubeBadge.Namespace)
+		})
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/b/team", nil)
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK || w.Body.String() != "shop" {
+			t.Fatalf("expected the shop badge, got %d %s", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("unknown alias", func(t *testing.T) {
+		router := gin.New()
+		router.Use(BadgeApiAccessMiddleware(kubeBadgeService, nil))
+		router.GET("/b/*alias", func(c *gin.Context) {})
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/b/unknown", nil)
+		router.ServeHTTP(w, req)
+
+		if !strings.Contains(w.Body.String(), unauthorizedSvg) {
+			t.Fatalf("expected response body to contain %s", unauthorizedSvg)
+		}
+	})
+
+	t.Run("authorized uptime", func(t *testing.T) {
+		router := gin.New()
+		router.Use(BadgeApiAccessMiddleware(kubeBadgeService, nil))
+		router.GET("/badges/authorized/uptime", func(c *gin.Context) {})
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/badges/authorized/uptime", nil)
+		router.ServeHTTP(w, req)
+
+		if strings.Contains(w.Body.String(), unauthorizedSvg) {
+			t.Fatalf("expected response body not to contain %s", unauthorizedSvg)
+		}
+	})
+
+	t.Run("status page", func(t *testing.T) {
+		router := gin.New()
+		router.Use(BadgeApiAccessMiddleware(kubeBadgeService, nil))
+		router.GET("/status", func(c *gin.Context) {})
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/status", nil)
+		router.ServeHTTP(w, req)
+
+		if strings.Contains(w.Body.String(), unauthorizedSvg) {
+			t.Fatalf("expected response body not to contain %s", unauthorizedSvg)
+		}
+	})
+
+	t.Run("signed badge", func(t *testing.T) {
+		secret := []byte("secret")
+		exp := time.Now().Add(time.Hour)
+		router := gin.New()
+		router.Use(BadgeApiAccessMiddleware(kubeBadgeService, secret))
+		router.GET("/badges/private", func(c *gin.Context) {})
+
+		tests := []struct {
+			name  string
+			query string
+			want  bool
+		}{
+			{"valid signature", signing.Query(secret, "/private", exp), true},
+			{"signature of another key", signing.Query(secret, "/other", exp), false},
+			{"expired signature", signing.Query(secret, "/private", time.Now().Add(-time.Minute)), false},
+			{"no signature", "", false},
+		}
+		for _, tt := range tests {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/badges/private?"+tt.query, nil)
+			router.ServeHTTP(w, req)
+
+			if got := !strings.Contains(w.Body.String(), unauthorizedSvg); got != tt.want {
+				t.Errorf("%s: served = %v, want %v", tt.name, got, tt.want)
+			}
+		}
+	})
 }
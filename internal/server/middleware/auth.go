@@ -0,0 +1,138 @@
+package middleware
+
+import (
+	"log/slog"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kubebadges/kubebadges/internal/auth"
+	"github.com/kubebadges/kubebadges/internal/config"
+)
+
+const (
+	userContextKey       = "kubebadges.user"
+	authorizerContextKey = "kubebadges.authorizer"
+)
+
+// AuthMiddleware authenticates the admin API. The token is read from an
+// "Authorization: Bearer" header, or from the password of Basic
+// authentication so that browsers can prompt for it. With a nil
+// authenticator every request passes, and with a nil authorizer every
+// authenticated user may use every verb.
+func AuthMiddleware(authenticator auth.Authenticator, authorizer auth.Authorizer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if authenticator == nil {
+			c.Next()
+			return
+		}
+
+		token := bearerToken(c.Request)
+		if len(token) == 0 {
+			unauthorized(c)
+			return
+		}
+		user, err := authenticator.Authenticate(c.Request.Context(), token)
+		if err != nil {
+			slog.Warn("authentication failed", "error", err)
+		}
+		if user == nil {
+			unauthorized(c)
+			return
+		}
+
+		c.Set(userContextKey, user)
+		if authorizer != nil {
+			c.Set(authorizerContextKey, authorizer)
+		}
+		c.Next()
+	}
+}
+
+// RequireVerb rejects requests whose user may not perform verb in the
+// kubebadges namespace.
+func RequireVerb(verb string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !Authorize(c, verb) {
+			return
+		}
+		c.Next()
+	}
+}
+
+// Authorize checks that the user of the request may perform verb in the
+// kubebadges namespace, see AuthorizeIn.
+func Authorize(c *gin.Context, verb string) bool {
+	return AuthorizeIn(c, config.KubeBadgeNamespace, verb)
+}
+
+// AuthorizeIn checks that the user of the request may perform verb on the
+// KubeBadges of namespace. When not, it aborts the request with 403 and
+// returns false.
+func AuthorizeIn(c *gin.Context, namespace, verb string) bool {
+	allowed, err := CanIn(c, namespace, verb)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return false
+	}
+	if !allowed {
+		user := c.Value(userContextKey).(*auth.User)
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "user " + user.Name + " cannot " + verb + " kubebadges in namespace " + namespace})
+		return false
+	}
+	return true
+}
+
+// CanIn reports whether the user of the request may perform verb on the
+// KubeBadges of namespace, without answering the request, for handlers
+// that leave out what the user may not see.
+func CanIn(c *gin.Context, namespace, verb string) (bool, error) {
+	authorizer, ok := c.Value(authorizerContextKey).(auth.Authorizer)
+	if !ok {
+		return true, nil
+	}
+	user := c.Value(userContextKey).(*auth.User)
+	return authorizer.Authorize(c.Request.Context(), user, namespace, verb)
+}
+
+// RequireContentType rejects POST, PUT and PATCH requests whose body is not
+// one of mediaTypes with 415. Browsers send Basic credentials on their own,
+// and a cross-site form can POST text/plain or form bodies without a CORS
+// preflight, so the admin API only accepts media types a form cannot send.
+func RequireContentType(mediaTypes ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodPost, http.MethodPut, http.MethodPatch:
+		default:
+			c.Next()
+			return
+		}
+		mediaType, _, err := mime.ParseMediaType(c.ContentType())
+		if err == nil {
+			for _, allowed := range mediaTypes {
+				if mediaType == allowed {
+					c.Next()
+					return
+				}
+			}
+		}
+		c.AbortWithStatusJSON(http.StatusUnsupportedMediaType, gin.H{"error": "content type must be one of " + strings.Join(mediaTypes, ", ")})
+	}
+}
+
+func bearerToken(r *http.Request) string {
+	if _, password, ok := r.BasicAuth(); ok {
+		return password
+	}
+	header := r.Header.Get("Authorization")
+	if token, ok := strings.CutPrefix(header, "Bearer "); ok {
+		return strings.TrimSpace(token)
+	}
+	return ""
+}
+
+func unauthorized(c *gin.Context) {
+	c.Header("WWW-Authenticate", `Basic realm="kubebadges"`)
+	c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+}
@@ -0,0 +1,139 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kubebadges/kubebadges/internal/auth"
+)
+
+type mockAuthorizer map[string]bool // key is user name + " " + namespace + " " + verb
+
+func (m mockAuthorizer) Authorize(_ context.Context, user *auth.User, namespace, verb string) (bool, error) {
+	return m[user.Name+" "+namespace+" "+verb], nil
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	authenticator := auth.StaticTokens{
+		"admin-token":  {Name: "admin"},
+		"viewer-token": {Name: "viewer"},
+	}
+	authorizer := mockAuthorizer{"admin kubebadges update": true}
+
+	tests := []struct {
+		name          string
+		authenticator auth.Authenticator
+		authorizer    auth.Authorizer
+		method        string
+		header        string
+		basicPassword string
+		want          int
+	}{
+		{"disabled", nil, nil, http.MethodPost, "", "", http.StatusOK},
+		{"no token", authenticator, authorizer, http.MethodGet, "", "", http.StatusUnauthorized},
+		{"unknown token", authenticator, authorizer, http.MethodGet, "Bearer other", "", http.StatusUnauthorized},
+		{"read with any user", authenticator, authorizer, http.MethodGet, "Bearer viewer-token", "", http.StatusOK},
+		{"basic password", authenticator, authorizer, http.MethodGet, "", "viewer-token", http.StatusOK},
+		{"write allowed", authenticator, authorizer, http.MethodPost, "Bearer admin-token", "", http.StatusOK},
+		{"write forbidden", authenticator, authorizer, http.MethodPost, "Bearer viewer-token", "", http.StatusForbidden},
+		{"write without authorizer", authenticator, nil, http.MethodPost, "Bearer viewer-token", "", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			api := router.Group("/api", AuthMiddleware(tt.authenticator, tt.authorizer))
+			api.GET("/badge", func(c *gin.Context) { c.String(http.StatusOK, "ok") })
+			api.POST("/badge", RequireVerb(auth.VerbUpdate), func(c *gin.Context) { c.String(http.StatusOK, "ok") })
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(tt.method, "/api/badge", nil)
+			if len(tt.header) > 0 {
+				req.Header.Set("Authorization", tt.header)
+			}
+			if len(tt.basicPassword) > 0 {
+				req.SetBasicAuth("", tt.basicPassword)
+			}
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.want {
+				t.Fatalf("expected status code %d, but got %d", tt.want, w.Code)
+			}
+			if w.Code == http.StatusUnauthorized && len(w.Header().Get("WWW-Authenticate")) == 0 {
+				t.Fatalf("expected a WWW-Authenticate header")
+			}
+		})
+	}
+}
+
+func TestRequireContentType(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		name        string
+		method      string
+		contentType string
+		want        int
+	}{
+		{"json", http.MethodPost, "application/json", http.StatusOK},
+		{"json with charset", http.MethodPost, "application/json; charset=utf-8", http.StatusOK},
+		{"yaml", http.MethodPut, "application/yaml", http.StatusOK},
+		{"form", http.MethodPost, "application/x-www-form-urlencoded", http.StatusUnsupportedMediaType},
+		{"text", http.MethodPost, "text/plain", http.StatusUnsupportedMediaType},
+		{"missing", http.MethodPatch, "", http.StatusUnsupportedMediaType},
+		{"read", http.MethodGet, "", http.StatusOK},
+		{"delete", http.MethodDelete, "", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(RequireContentType("application/json", "application/yaml"))
+			router.Handle(tt.method, "/api/badge", func(c *gin.Context) { c.String(http.StatusOK, "ok") })
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(tt.method, "/api/badge", nil)
+			if len(tt.contentType) > 0 {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			router.ServeHTTP(w, req)
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuthorizeIn(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	authenticator := auth.StaticTokens{"shop-token": {Name: "shop-admin"}}
+	authorizer := mockAuthorizer{"shop-admin shop update": true}
+
+	tests := []struct {
+		namespace string
+		want      int
+	}{
+		{"shop", http.StatusOK},
+		{"billing", http.StatusForbidden},
+		{"kubebadges", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.namespace, func(t *testing.T) {
+			router := gin.New()
+			router.PUT("/api/badges/:name", AuthMiddleware(authenticator, authorizer), func(c *gin.Context) {
+				if AuthorizeIn(c, c.Query("namespace"), auth.VerbUpdate) {
+					c.String(http.StatusOK, "ok")
+				}
+			})
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPut, "/api/badges/api?namespace="+tt.namespace, nil)
+			req.Header.Set("Authorization", "Bearer shop-token")
+			router.ServeHTTP(w, req)
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+		})
+	}
+}
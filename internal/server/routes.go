@@ -3,12 +3,16 @@ package server
 import (
 	"embed"
 	"net/http"
+	"net/url"
 	"path/filepath"
 	"strings"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	"github.com/kubebadges/kubebadges"
+	"github.com/kubebadges/kubebadges/internal/auth"
+	"github.com/kubebadges/kubebadges/internal/metrics"
+	"github.com/kubebadges/kubebadges/internal/notify"
 	"github.com/kubebadges/kubebadges/internal/server/controller"
 	"github.com/kubebadges/kubebadges/internal/server/middleware"
 )
@@ -65,40 +69,83 @@ func serveFile(c *gin.Context, fs embed.FS, filePath, mineType string, mineTypeO
 	}
 }
 
+// localOrigin allows the dashboard to be developed on a local web server.
+// The host is compared exactly, so origins such as
+// http://localhost.example.com do not pass.
+func localOrigin(origin string) bool {
+	u, err := url.Parse(origin)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return false
+	}
+	switch u.Hostname() {
+	case "localhost", "127.0.0.1", "::1":
+		return true
+	}
+	return false
+}
+
 func (s *Server) initRouter() {
 	baseCtrl := &controller.BaseController{
 		ServerContext: s.svcCtx,
 	}
 	kubeController := controller.NewKubeController(s.svcCtx)
 	badgesController := controller.NewBadgesController(baseCtrl)
+	metrics.RegisterBadgeStatus(badgesController.BadgeStatuses)
+
+	go s.svcCtx.HistoryService.Run(badgesController.AllBadgeStatuses)
+	go s.svcCtx.StreamService.Run(badgesController.AllBadgeStatuses)
+	if s.svcCtx.Config.StatusInterval > 0 {
+		go s.svcCtx.StatusWriter.Run(badgesController.AllBadgeStatuses)
+	}
+	if notifier := notify.NewNotifier(s.svcCtx.Config, badgesController.BadgeStatuses); notifier.Enabled() {
+		go notifier.Run()
+	}
 
 	registerStaticFiles(s.internalEngine, kubebadges.WebFiles, "web")
+	s.internalEngine.GET("/metrics", gin.WrapH(metrics.Handler()))
+	s.internalEngine.Use(metrics.Middleware("internal"))
 
 	s.internalEngine.Use(cors.New(cors.Config{
-		AllowOrigins:     []string{"*"},
 		AllowMethods:     []string{"PUT", "PATCH", "POST", "GET", "DELETE"},
 		AllowHeaders:     []string{"Origin", "Push-Id", "App", "App-Version", "X-Device-Id", "Content-Type", "Content-Length", "Authorization", "X-App-Name"},
 		ExposeHeaders:    []string{"*"},
 		AllowCredentials: true,
-		AllowOriginFunc: func(origin string) bool {
-			return strings.Contains(origin, "localhost") || strings.Contains(origin, "127.0.0.1")
-		},
+		AllowOriginFunc:  localOrigin,
 	}))
 
 	// admin routes
-	api := s.internalEngine.Group("/api")
+	api := s.internalEngine.Group("/api",
+		middleware.RequireContentType("application/json", "application/yaml"),
+		middleware.AuthMiddleware(s.svcCtx.Authenticator, s.svcCtx.Authorizer))
 	{
 		api.GET("/nodes", kubeController.ListNodes)
 		api.GET("/namespaces", kubeController.ListNamespaces)
 		api.GET("/deployments/:namespace", kubeController.ListDeployments)
-		api.POST("/badge", kubeController.UpdateBadge)
+		api.GET("/statefulsets/:namespace", kubeController.ListStatefulSets)
+		api.GET("/daemonsets/:namespace", kubeController.ListDaemonSets)
+		api.POST("/badge", middleware.RequireVerb(auth.VerbUpdate), kubeController.UpdateBadge)
+		api.POST("/badge/sign", middleware.RequireVerb(auth.VerbSign), kubeController.SignBadge)
 		api.GET("/config", kubeController.GetConfig)
-		api.POST("/config", kubeController.UpdateConfig)
+		api.POST("/config", middleware.RequireVerb(auth.VerbConfigure), kubeController.UpdateConfig)
 
 		// List Kustomizations (optional)
 		api.GET("/kustomizations/:namespace", kubeController.ListKustomizations)
 		api.GET("/postgresqls/:namespace", kubeController.ListPostgresqls)
 		api.GET("/jobs/:namespace", kubeController.ListJobs)
+		api.GET("/cronjobs/:namespace", kubeController.ListCronJobs)
+		api.GET("/custom/:group/:resource/:namespace", kubeController.ListCustomResources)
+		api.GET("/history", kubeController.GetHistory)
+		// the badges API authorizes in the namespace of each badge
+		api.GET("/badges", kubeController.ListBadges)
+		api.POST("/badges", kubeController.CreateBadge)
+		api.GET("/badges/orphans", kubeController.ListOrphans)
+		api.GET("/badges/:name", kubeController.GetBadge)
+		api.PUT("/badges/:name", kubeController.ReplaceBadge)
+		api.PATCH("/badges/:name", kubeController.PatchBadge)
+		api.DELETE("/badges/:name", kubeController.DeleteBadge)
+		api.GET("/export", kubeController.Export)
+		api.POST("/import", kubeController.Import)
+		api.GET("/events", badgesController.Stream(false))
 	}
 
 	badges := s.internalEngine.Group("/badges")
@@ -106,29 +153,81 @@ func (s *Server) initRouter() {
 		// badges routes
 		badges.GET("/kube/node/:node", badgesController.Node)
 		badges.GET("/kube/namespace/:namespace", badgesController.Namespace)
+		badges.GET("/kube/summary/:namespace", badgesController.Summary)
 		badges.GET("/kube/deployment/:namespace/:deployment", badgesController.Deployment)
 		badges.GET("/kube/pod/:namespace/:pod", badgesController.Pod)
+		badges.GET("/kube/statefulset/:namespace/:statefulset", badgesController.StatefulSet)
+		badges.GET("/kube/daemonset/:namespace/:daemonset", badgesController.DaemonSet)
 
 		badges.GET("/kube/kustomization/:namespace/:kustomization", badgesController.Kustomization)
 		badges.GET("/kube/postgresql/:namespace/:postgresql", badgesController.Postgresql)
 		badges.GET("/kube/job/:namespace/:job", badgesController.Job)
+		badges.GET("/kube/cronjob/:namespace/:cronjob", badgesController.CronJob)
+		badges.GET("/kube/custom/:group/:resource/:namespace/:name", badgesController.Custom)
+		badges.GET("/probe/:name", badgesController.Probe)
+		badges.GET("/group/:name", badgesController.Group)
+
+		// uptime of any badge above
+		badges.GET("/kube/node/:node/uptime", badgesController.Uptime)
+		badges.GET("/kube/namespace/:namespace/uptime", badgesController.Uptime)
+		badges.GET("/kube/summary/:namespace/uptime", badgesController.Uptime)
+		badges.GET("/kube/deployment/:namespace/:deployment/uptime", badgesController.Uptime)
+		badges.GET("/kube/pod/:namespace/:pod/uptime", badgesController.Uptime)
+		badges.GET("/kube/statefulset/:namespace/:statefulset/uptime", badgesController.Uptime)
+		badges.GET("/kube/daemonset/:namespace/:daemonset/uptime", badgesController.Uptime)
+		badges.GET("/kube/kustomization/:namespace/:kustomization/uptime", badgesController.Uptime)
+		badges.GET("/kube/postgresql/:namespace/:postgresql/uptime", badgesController.Uptime)
+		badges.GET("/kube/job/:namespace/:job/uptime", badgesController.Uptime)
+		badges.GET("/kube/cronjob/:namespace/:cronjob/uptime", badgesController.Uptime)
+		badges.GET("/kube/custom/:group/:resource/:namespace/:name/uptime", badgesController.Uptime)
+		badges.GET("/probe/:name/uptime", badgesController.Uptime)
+		badges.GET("/group/:name/uptime", badgesController.Uptime)
 	}
+	s.internalEngine.GET("/b/*alias", badgesController.Alias(s.internalEngine))
 
 	// for external api
 	s.externalEngine.NoRoute(func(ctx *gin.Context) {
 		baseCtrl.NotFound(ctx)
 	})
-	s.externalEngine.Use(middleware.BadgeApiAccessMiddleware(s.svcCtx.KubeBadgesService))
+	s.externalEngine.Use(metrics.Middleware("external"))
+	s.externalEngine.Use(middleware.BadgeApiAccessMiddleware(s.svcCtx.KubeBadgesService, []byte(s.svcCtx.Config.SigningKey)))
 	exBadges := s.externalEngine.Group("/badges")
 	{
 		exBadges.GET("/kube/node/:node", badgesController.Node)
 		exBadges.GET("/kube/namespace/:namespace", badgesController.Namespace)
+		exBadges.GET("/kube/summary/:namespace", badgesController.Summary)
 		exBadges.GET("/kube/deployment/:namespace/:deployment", badgesController.Deployment)
 		exBadges.GET("/kube/pod/:namespace/:pod", badgesController.Pod)
 		exBadges.GET("/kube/pod/:namespace/:pod/status", badgesController.Pod)
+		exBadges.GET("/kube/statefulset/:namespace/:statefulset", badgesController.StatefulSet)
+		exBadges.GET("/kube/daemonset/:namespace/:daemonset", badgesController.DaemonSet)
 
 		exBadges.GET("/kube/kustomization/:namespace/:kustomization", badgesController.Kustomization)
 		exBadges.GET("/kube/job/:namespace/:job", badgesController.Job)
+		exBadges.GET("/kube/cronjob/:namespace/:cronjob", badgesController.CronJob)
+		exBadges.GET("/kube/custom/:group/:resource/:namespace/:name", badgesController.Custom)
 		exBadges.GET("/kube/postgresql/:namespace/:postgresql", badgesController.Postgresql)
+		exBadges.GET("/probe/:name", badgesController.Probe)
+		exBadges.GET("/group/:name", badgesController.Group)
+
+		exBadges.GET("/kube/node/:node/uptime", badgesController.Uptime)
+		exBadges.GET("/kube/namespace/:namespace/uptime", badgesController.Uptime)
+		exBadges.GET("/kube/summary/:namespace/uptime", badgesController.Uptime)
+		exBadges.GET("/kube/deployment/:namespace/:deployment/uptime", badgesController.Uptime)
+		exBadges.GET("/kube/pod/:namespace/:pod/uptime", badgesController.Uptime)
+		exBadges.GET("/kube/statefulset/:namespace/:statefulset/uptime", badgesController.Uptime)
+		exBadges.GET("/kube/daemonset/:namespace/:daemonset/uptime", badgesController.Uptime)
+		exBadges.GET("/kube/kustomization/:namespace/:kustomization/uptime", badgesController.Uptime)
+		exBadges.GET("/kube/job/:namespace/:job/uptime", badgesController.Uptime)
+		exBadges.GET("/kube/cronjob/:namespace/:cronjob/uptime", badgesController.Uptime)
+		exBadges.GET("/kube/custom/:group/:resource/:namespace/:name/uptime", badgesController.Uptime)
+		exBadges.GET("/kube/postgresql/:namespace/:postgresql/uptime", badgesController.Uptime)
+		exBadges.GET("/probe/:name/uptime", badgesController.Uptime)
+		exBadges.GET("/group/:name/uptime", badgesController.Uptime)
+	}
+	s.externalEngine.GET("/b/*alias", badgesController.Alias(s.externalEngine))
+	s.externalEngine.GET("/status", badgesController.StatusPage)
+	if s.svcCtx.Config.StreamExternal {
+		s.externalEngine.GET("/events", badgesController.Stream(true))
 	}
 }
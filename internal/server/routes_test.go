@@ -66,3 +66,26 @@ func TestRegisterStaticFiles(t *testing.T) {
 		})
 	}
 }
+
+func TestLocalOrigin(t *testing.T) {
+	tests := []struct {
+		origin string
+		want   bool
+	}{
+		{"http://localhost:3000", true},
+		{"https://127.0.0.1", true},
+		{"http://[::1]:8080", true},
+		{"http://localhost.example.com", false},
+		{"https://example.com/localhost", false},
+		{"http://127.0.0.1.example.com", false},
+		{"file://localhost", false},
+		{"null", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.origin, func(t *testing.T) {
+			if got := localOrigin(tt.origin); got != tt.want {
+				t.Errorf("localOrigin(%q) = %v, want %v", tt.origin, got, tt.want)
+			}
+		})
+	}
+}
@@ -1,38 +1,106 @@
 package svc
 
 import (
+	"time"
+
+	"github.com/kubebadges/kubebadges/internal/auth"
 	"github.com/kubebadges/kubebadges/internal/badges"
 	"github.com/kubebadges/kubebadges/internal/config"
 	"github.com/kubebadges/kubebadges/internal/k8s"
+	"github.com/kubebadges/kubebadges/internal/metrics"
 	"github.com/kubebadges/kubebadges/internal/service"
 	"github.com/kubebadges/kubebadges/internal/utils"
 )
 
 type ServerContext struct {
-	KubeHelper        *k8s.KubeHelper
-	BadgesHelper      *badges.BadgesHelper
-	Config            *config.Config
-	KubeBadgesService *service.KubeBadgesService
+	KubeHelper             *k8s.KubeHelper
+	BadgesHelper           *badges.BadgesHelper
+	Config                 *config.Config
+	KubeBadgesService      *service.KubeBadgesService
+	CustomResourcesService *service.CustomResourcesService
+	ProbeService           *service.ProbeService
+	HistoryService         *service.HistoryService
+	StreamService          *service.StreamService
+	StatusWriter           *service.StatusWriter
+	OrphanService          *service.OrphanService
+	Authenticator          auth.Authenticator
+	Authorizer             auth.Authorizer
 }
 
 func NewServerContext() *ServerContext {
 	// load data
 	config := &config.Config{}
+	config.BadgeBackend = utils.GetEnv("BADGE_BACKEND", "native")
 	config.ShieldsScheme = utils.GetEnv("SHIELDS_SCHEME", "http")
 	config.ShieldsHost = utils.GetEnv("SHIELDS_HOST", "127.0.0.1:8081")
 	config.CacheTime = utils.GetEnvAsInt("CACHE_TIME", 300)
 	config.BadgeCacheTime = utils.GetEnvAsInt("BADGE_CACHE_TIME", 300)
+	config.CronJobMaxAge = utils.GetEnvAsInt("CRONJOB_MAX_AGE", 0)
+	config.NotifyWebhookURLs = utils.GetEnvAsSlice("NOTIFY_WEBHOOK_URLS")
+	config.NotifySlackURLs = utils.GetEnvAsSlice("NOTIFY_SLACK_URLS")
+	config.NotifyTeamsURLs = utils.GetEnvAsSlice("NOTIFY_TEAMS_URLS")
+	config.NotifyInterval = utils.GetEnvAsInt("NOTIFY_INTERVAL", 30)
+	config.NotifyDebounce = utils.GetEnvAsInt("NOTIFY_DEBOUNCE", 120)
+	config.HistoryInterval = utils.GetEnvAsInt("HISTORY_INTERVAL", 60)
+	config.OrphanInterval = utils.GetEnvAsInt("ORPHAN_INTERVAL", 300)
+	config.OrphanGracePeriod = utils.GetEnvAsInt("ORPHAN_GRACE_PERIOD", 0)
+	config.StatusInterval = utils.GetEnvAsInt("STATUS_INTERVAL", 60)
+	config.StatusWriteRate = utils.GetEnvAsInt("STATUS_WRITE_RATE", 5)
+	config.StreamExternal = utils.GetEnvAsBool("STREAM_EXTERNAL", false)
+	config.SigningKey = utils.GetEnv("SIGNING_KEY", "")
+	config.AuthTokenFile = utils.GetEnv("AUTH_TOKEN_FILE", "")
+	config.AuthTokenReview = utils.GetEnvAsBool("AUTH_TOKEN_REVIEW", false)
+	config.AuthOIDCIssuerURL = utils.GetEnv("AUTH_OIDC_ISSUER_URL", "")
+	config.AuthOIDCClientID = utils.GetEnv("AUTH_OIDC_CLIENT_ID", "")
+	config.AuthOIDCUsernameClaim = utils.GetEnv("AUTH_OIDC_USERNAME_CLAIM", "sub")
+	config.AuthOIDCGroupsClaim = utils.GetEnv("AUTH_OIDC_GROUPS_CLAIM", "groups")
+	config.AuthOIDCUsernamePrefix = utils.GetEnv("AUTH_OIDC_USERNAME_PREFIX", "")
+	config.AuthOIDCGroupsPrefix = utils.GetEnv("AUTH_OIDC_GROUPS_PREFIX", "")
+	config.AuthAuthorize = utils.GetEnvAsBool("AUTH_AUTHORIZE", true)
 
 	kubeHelper := k8s.NewKubeHelper()
 	kubeHelper.Init()
+	kubeHelper.StartInformers(time.Duration(config.CacheTime) * time.Second)
 
 	kubeBadgeService := service.NewKubeBadgesService(kubeHelper)
 	go kubeBadgeService.Run()
 
+	metrics.RegisterInformer("kubebadges", kubeBadgeService.HasSynced)
+	metrics.RegisterInformer("resources", kubeHelper.InformersSynced)
+
+	probeService := service.NewProbeService(kubeBadgeService)
+	go probeService.Run()
+
+	go service.NewAnnotationService(kubeHelper, kubeBadgeService).Run()
+
+	customResourcesService := service.NewCustomResourcesService(kubeHelper)
+	orphanService := service.NewOrphanService(kubeHelper, kubeBadgeService, customResourcesService,
+		time.Duration(config.OrphanInterval)*time.Second, time.Duration(config.OrphanGracePeriod)*time.Second)
+	if config.OrphanInterval > 0 {
+		go orphanService.Run()
+	}
+
+	authenticator, err := auth.NewAuthenticator(config, kubeHelper)
+	if err != nil {
+		panic(err.Error())
+	}
+	var authorizer auth.Authorizer
+	if authenticator != nil && config.AuthAuthorize {
+		authorizer = auth.NewSubjectAccessReview(kubeHelper)
+	}
+
 	return &ServerContext{
-		Config:            config,
-		KubeHelper:        kubeHelper,
-		BadgesHelper:      badges.NewBadgesHelper(config),
-		KubeBadgesService: kubeBadgeService,
+		Config:                 config,
+		KubeHelper:             kubeHelper,
+		BadgesHelper:           badges.NewBadgesHelper(config),
+		KubeBadgesService:      kubeBadgeService,
+		CustomResourcesService: customResourcesService,
+		ProbeService:           probeService,
+		HistoryService:         service.NewHistoryService(kubeHelper, time.Duration(config.HistoryInterval)*time.Second),
+		StreamService:          service.NewStreamService(kubeHelper, kubeBadgeService),
+		StatusWriter:           service.NewStatusWriter(kubeHelper, kubeBadgeService, time.Duration(config.StatusInterval)*time.Second, config.StatusWriteRate),
+		OrphanService:          orphanService,
+		Authenticator:          authenticator,
+		Authorizer:             authorizer,
 	}
 }
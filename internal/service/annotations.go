@@ -0,0 +1,209 @@
+package service
+
+import (
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kubebadges/kubebadges/internal/k8s"
+	v1 "github.com/kubebadges/kubebadges/pkg/apis/kubebadges/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/workqueue"
+)
+
+// Annotations that publish the badge of a workload or namespace.
+const (
+	AnnotationPrefix      = "kubebadges.io/"
+	AnnotationAllowed     = AnnotationPrefix + "allowed"
+	AnnotationDisplayName = AnnotationPrefix + "display-name"
+	AnnotationAlias       = AnnotationPrefix + "alias"
+	AnnotationNotify      = AnnotationPrefix + "notify"
+	AnnotationStatusGroup = AnnotationPrefix + "status-group"
+)
+
+// annotatedKinds are the kinds watched for annotations, with the kind of
+// the owner reference set on their KubeBadges.
+var annotatedKinds = map[string]schema.GroupVersionKind{
+	"namespace":   {Version: "v1", Kind: "Namespace"},
+	"deployment":  {Group: "apps", Version: "v1", Kind: "Deployment"},
+	"statefulset": {Group: "apps", Version: "v1", Kind: "StatefulSet"},
+	"daemonset":   {Group: "apps", Version: "v1", Kind: "DaemonSet"},
+	"job":         {Group: "batch", Version: "v1", Kind: "Job"},
+	"cronjob":     {Group: "batch", Version: "v1", Kind: "CronJob"},
+}
+
+// AnnotationService publishes badges from kubebadges.io annotations. For
+// every annotated object it keeps a KubeBadge in the object's namespace,
+// controlled by the object, and deletes the KubeBadge once the annotations
+// or the object are gone. KubeBadges it did not create are left alone.
+type AnnotationService struct {
+	kubeHelper        *k8s.KubeHelper
+	kubeBadgesService *KubeBadgesService
+	queue             workqueue.RateLimitingInterface // items are badge keys
+}
+
+func NewAnnotationService(kubeHelper *k8s.KubeHelper, kubeBadgesService *KubeBadgesService) *AnnotationService {
+	service := &AnnotationService{
+		kubeHelper:        kubeHelper,
+		kubeBadgesService: kubeBadgesService,
+		queue:             workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter()),
+	}
+	kubeHelper.OnObjectChange(func(kind string, obj metav1.Object) {
+		service.queue.Add(annotatedKey(kind, obj.GetNamespace(), obj.GetName()))
+	})
+	return service
+}
+
+func annotatedKey(kind, namespace, name string) string {
+	if kind == "namespace" {
+		return "/kube/namespace/" + name
+	}
+	return "/kube/" + kind + "/" + namespace + "/" + name
+}
+
+func (a *AnnotationService) Run() {
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	defer a.queue.ShutDown()
+
+	// existing KubeBadges must be known before deciding to create one, and
+	// the objects are read from the informers
+	for !a.kubeBadgesService.HasSynced() || !a.kubeHelper.InformersSynced() {
+		time.Sleep(time.Second)
+	}
+
+	wait.Until(a.runWorker, time.Second, stopCh)
+}
+
+func (a *AnnotationService) runWorker() {
+	for a.processNextItem() {
+	}
+}
+
+func (a *AnnotationService) processNextItem() bool {
+	item, quit := a.queue.Get()
+	if quit {
+		return false
+	}
+	defer a.queue.Done(item)
+
+	key := item.(string)
+	if err := a.reconcile(key); err != nil {
+		slog.Warn("failed to reconcile annotated badge", slog.String("key", key), slog.String("error", err.Error()))
+		a.queue.AddRateLimited(item)
+		return true
+	}
+	a.queue.Forget(item)
+	return true
+}
+
+func (a *AnnotationService) reconcile(key string) error {
+	segments := strings.Split(strings.Trim(key, "/"), "/")
+	kind, namespace, name := segments[1], segments[2], segments[len(segments)-1]
+	if kind == "namespace" {
+		namespace = name
+	}
+
+	obj, err := a.getObject(kind, namespace, name)
+	if apierrors.IsNotFound(err) {
+		obj, err = nil, nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var spec v1.KubeBadgeSpec
+	annotated := false
+	if obj != nil {
+		spec, annotated = specFromAnnotations(obj.GetAnnotations())
+		spec.Type = kind
+		spec.OriginalURL = key
+		spec.OwnerNamespace = namespace
+	}
+
+	badgeName := a.kubeBadgesService.GenerateKubeBadgeName(key)
+	existing, found := a.kubeBadgesService.GetKubeBadgeIn(namespace, badgeName)
+	if found && !controlledBy(existing, annotatedKinds[kind].Kind, name) {
+		if annotated {
+			slog.Info("kubebadge exists and is not managed by annotations, skip", slog.String("namespace", namespace), slog.String("name", badgeName))
+		}
+		return nil
+	}
+
+	switch {
+	case !annotated && found:
+		slog.Info("deleting annotated kubebadge", slog.String("namespace", namespace), slog.String("name", badgeName))
+		if err := a.kubeHelper.DeleteKubeBadgeIn(namespace, badgeName); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+	case annotated && !found:
+		slog.Info("creating annotated kubebadge", slog.String("namespace", namespace), slog.String("name", badgeName))
+		owner := metav1.NewControllerRef(obj, annotatedKinds[kind])
+		// blocking the owner's deletion needs permissions we do not ask for
+		owner.BlockOwnerDeletion = nil
+		if _, err := a.kubeHelper.CreateOwnedKubeBadge(namespace, spec, *owner); err != nil {
+			return err
+		}
+	case annotated && found && !sameAnnotatedSpec(existing.Spec, spec):
+		kubeBadge := existing.DeepCopy()
+		kubeBadge.Spec.Allowed = spec.Allowed
+		kubeBadge.Spec.DisplayName = spec.DisplayName
+		kubeBadge.Spec.AliasURL = spec.AliasURL
+		kubeBadge.Spec.Notify = spec.Notify
+		kubeBadge.Spec.StatusGroup = spec.StatusGroup
+		if _, err := a.kubeHelper.UpdateKubeBadge(kubeBadge); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (a *AnnotationService) getObject(kind, namespace, name string) (metav1.Object, error) {
+	switch kind {
+	case "namespace":
+		return a.kubeHelper.GetNamespace(name)
+	case "deployment":
+		return a.kubeHelper.GetDeployment(namespace, name)
+	case "statefulset":
+		return a.kubeHelper.GetStatefulSet(namespace, name)
+	case "daemonset":
+		return a.kubeHelper.GetDaemonSet(namespace, name)
+	case "job":
+		return a.kubeHelper.GetJob(namespace, name)
+	default:
+		return a.kubeHelper.GetCronJob(namespace, name)
+	}
+}
+
+// specFromAnnotations returns the badge settings of the kubebadges.io
+// annotations. The boolean result is false when there are none.
+func specFromAnnotations(annotations map[string]string) (v1.KubeBadgeSpec, bool) {
+	var spec v1.KubeBadgeSpec
+	annotated := false
+	for key := range annotations {
+		if strings.HasPrefix(key, AnnotationPrefix) {
+			annotated = true
+			break
+		}
+	}
+	spec.Allowed, _ = strconv.ParseBool(annotations[AnnotationAllowed])
+	spec.Notify, _ = strconv.ParseBool(annotations[AnnotationNotify])
+	spec.DisplayName = annotations[AnnotationDisplayName]
+	spec.AliasURL = annotations[AnnotationAlias]
+	spec.StatusGroup = annotations[AnnotationStatusGroup]
+	return spec, annotated
+}
+
+func sameAnnotatedSpec(a, b v1.KubeBadgeSpec) bool {
+	return a.Allowed == b.Allowed && a.DisplayName == b.DisplayName && a.AliasURL == b.AliasURL &&
+		a.Notify == b.Notify && a.StatusGroup == b.StatusGroup
+}
+
+func controlledBy(kubeBadge *v1.KubeBadge, kind, name string) bool {
+	owner := metav1.GetControllerOf(kubeBadge)
+	return owner != nil && owner.Kind == kind && owner.Name == name
+}
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+
+	v1 "github.com/kubebadges/kubebadges/pkg/apis/kubebadges/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSpecFromAnnotations(t *testing.T) {
+	tests := []struct {
+		name          string
+		annotations   map[string]string
+		want          v1.KubeBadgeSpec
+		wantAnnotated bool
+	}{
+		{
+			name:        "no annotations",
+			annotations: nil,
+		},
+		{
+			name:        "other annotations",
+			annotations: map[string]string{"deployment.kubernetes.io/revision": "3"},
+		},
+		{
+			name: "all annotations",
+			annotations: map[string]string{
+				AnnotationAllowed:     "true",
+				AnnotationDisplayName: "Checkout",
+				AnnotationAlias:       "checkout",
+				AnnotationNotify:      "true",
+				AnnotationStatusGroup: "Shop",
+			},
+			want:          v1.KubeBadgeSpec{Allowed: true, DisplayName: "Checkout", AliasURL: "checkout", Notify: true, StatusGroup: "Shop"},
+			wantAnnotated: true,
+		},
+		{
+			name:          "alias only",
+			annotations:   map[string]string{AnnotationAlias: "checkout"},
+			want:          v1.KubeBadgeSpec{AliasURL: "checkout"},
+			wantAnnotated: true,
+		},
+		{
+			name:          "invalid allowed",
+			annotations:   map[string]string{AnnotationAllowed: "yes please"},
+			want:          v1.KubeBadgeSpec{},
+			wantAnnotated: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, annotated := specFromAnnotations(tt.annotations)
+			if annotated != tt.wantAnnotated {
+				t.Fatalf("specFromAnnotations() annotated = %v, want %v", annotated, tt.wantAnnotated)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("specFromAnnotations() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAnnotatedKey(t *testing.T) {
+	if got := annotatedKey("deployment", "shop", "api"); got != "/kube/deployment/shop/api" {
+		t.Errorf("annotatedKey() = %q", got)
+	}
+	if got := annotatedKey("namespace", "", "shop"); got != "/kube/namespace/shop" {
+		t.Errorf("annotatedKey() = %q", got)
+	}
+}
+
+func TestControlledBy(t *testing.T) {
+	isController := true
+	kubeBadge := &v1.KubeBadge{ObjectMeta: metav1.ObjectMeta{OwnerReferences: []metav1.OwnerReference{
+		{Kind: "Deployment", Name: "api", Controller: &isController},
+	}}}
+
+	tests := []struct {
+		name      string
+		kubeBadge *v1.KubeBadge
+		kind      string
+		owner     string
+		want      bool
+	}{
+		{"controller", kubeBadge, "Deployment", "api", true},
+		{"other name", kubeBadge, "Deployment", "web", false},
+		{"other kind", kubeBadge, "StatefulSet", "api", false},
+		{"no owner", &v1.KubeBadge{}, "Deployment", "api", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := controlledBy(tt.kubeBadge, tt.kind, tt.owner); got != tt.want {
+				t.Errorf("controlledBy() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
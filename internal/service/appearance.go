@@ -0,0 +1,66 @@
+package service
+
+import (
+	"io"
+	"log/slog"
+	"text/template"
+	"time"
+
+	v1 "github.com/kubebadges/kubebadges/pkg/apis/kubebadges/v1"
+)
+
+// MessageTemplateData is what spec.appearance.messageTemplate is executed
+// with: the label, the computed message and the color drawn.
+type MessageTemplateData struct {
+	Label   string
+	Message string
+	Color   string
+}
+
+// messageTemplate is a parsed message template and the source it was
+// parsed from. tmpl is nil when the source is invalid.
+type messageTemplate struct {
+	source string
+	tmpl   *template.Template
+}
+
+// ParseMessageTemplate parses a message template. It is also executed once
+// with empty data, so that fields the data lacks are reported here rather
+// than on every request.
+func ParseMessageTemplate(source string) (*template.Template, error) {
+	tmpl, err := template.New("message").Parse(source)
+	if err != nil {
+		return nil, err
+	}
+	if err := tmpl.Execute(io.Discard, MessageTemplateData{}); err != nil {
+		return nil, err
+	}
+	return tmpl, nil
+}
+
+// loadMessageTemplate parses the message template of a KubeBadge and keeps
+// it until the KubeBadge changes. Invalid templates are logged and
+// ignored.
+func (k *KubeBadgesService) loadMessageTemplate(kubebadge *v1.KubeBadge) messageTemplate {
+	loaded := messageTemplate{source: kubebadge.Spec.Appearance.MessageTemplate}
+	if len(loaded.source) > 0 {
+		tmpl, err := ParseMessageTemplate(loaded.source)
+		if err != nil {
+			slog.Warn("invalid message template", slog.String("namespace", kubebadge.Namespace),
+				slog.String("name", kubebadge.Name), slog.String("error", err.Error()))
+		}
+		loaded.tmpl = tmpl
+	}
+	k.messageTemplates.Set(cacheKey(kubebadge.Namespace, kubebadge.Name), loaded, 48*time.Hour)
+	return loaded
+}
+
+// MessageTemplate returns the parsed spec.appearance.messageTemplate of a
+// KubeBadge, or nil when it has none or it is invalid.
+func (k *KubeBadgesService) MessageTemplate(kubebadge *v1.KubeBadge) *template.Template {
+	loaded, ok := k.messageTemplates.Get(cacheKey(kubebadge.Namespace, kubebadge.Name))
+	if !ok || loaded.source != kubebadge.Spec.Appearance.MessageTemplate {
+		loaded = k.loadMessageTemplate(kubebadge)
+	}
+	return loaded.tmpl
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"errors"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kubebadges/kubebadges/internal/config"
+	"github.com/kubebadges/kubebadges/internal/k8s"
+	"github.com/kubebadges/kubebadges/internal/model"
+	"sigs.k8s.io/yaml"
+)
+
+// customResourcesReload is how long definitions are kept before the
+// kubebadge-config ConfigMap is read again.
+const customResourcesReload = 30 * time.Second
+
+// CustomResourcesService loads the custom resource badge definitions from the
+// kubebadge-config ConfigMap and makes sure their resources are watched.
+type CustomResourcesService struct {
+	kubeHelper *k8s.KubeHelper
+
+	mu          sync.Mutex
+	raw         string
+	definitions []model.CustomResourceBadge
+	loadedAt    time.Time
+}
+
+func NewCustomResourcesService(kubeHelper *k8s.KubeHelper) *CustomResourcesService {
+	return &CustomResourcesService{
+		kubeHelper: kubeHelper,
+	}
+}
+
+// ParseCustomResources parses the YAML list of definitions stored in the
+// ConfigMap. Each definition needs a version, a resource and either a
+// condition type or a JSONPath expression.
+func ParseCustomResources(raw string) ([]model.CustomResourceBadge, error) {
+	var definitions []model.CustomResourceBadge
+	if err := yaml.Unmarshal([]byte(raw), &definitions); err != nil {
+		return nil, err
+	}
+	for _, definition := range definitions {
+		if len(definition.Version) == 0 || len(definition.Resource) == 0 {
+			return nil, errors.New("custom resource definition requires version and resource")
+		}
+		if len(definition.ConditionType) == 0 && len(definition.JSONPath) == 0 {
+			return nil, errors.New("custom resource definition requires condition_type or json_path")
+		}
+	}
+	return definitions, nil
+}
+
+// Definitions returns the current definitions. A ConfigMap that fails to
+// parse keeps the previously loaded definitions in place.
+func (s *CustomResourcesService) Definitions() []model.CustomResourceBadge {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if time.Since(s.loadedAt) < customResourcesReload {
+		return s.definitions
+	}
+	s.loadedAt = time.Now()
+
+	configMap, err := s.kubeHelper.GetConfig()
+	if err != nil {
+		return s.definitions
+	}
+	raw := configMap.Data[config.KubeBadgeConfigCustomResourcesKey]
+	if raw == s.raw {
+		return s.definitions
+	}
+
+	definitions, err := ParseCustomResources(raw)
+	if err != nil {
+		slog.Error("invalid custom resources config", "error", err)
+		return s.definitions
+	}
+	for _, definition := range definitions {
+		s.kubeHelper.WatchResource(definition.GVR())
+	}
+	s.raw = raw
+	s.definitions = definitions
+	return s.definitions
+}
+
+// GetDefinition returns the definition for a group and resource. The core
+// API group is addressed as "core" since it has an empty name.
+func (s *CustomResourcesService) GetDefinition(group, resource string) (*model.CustomResourceBadge, error) {
+	if group == "core" {
+		group = ""
+	}
+	for _, definition := range s.Definitions() {
+		if definition.Group == group && strings.EqualFold(definition.Resource, resource) {
+			return &definition, nil
+		}
+	}
+	return nil, errors.New("not found")
+}
@@ -0,0 +1,58 @@
+package service
+
+import (
+	"testing"
+)
+
+func TestParseCustomResources(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		want    int
+		wantErr bool
+	}{
+		{
+			name: "empty",
+			raw:  "",
+			want: 0,
+		},
+		{
+			name: "condition and jsonpath",
+			raw: `
+- group: cert-manager.io
+  version: v1
+  resource: certificates
+  condition_type: Ready
+- group: argoproj.io
+  version: v1alpha1
+  resource: applications
+  json_path: .status.sync.status
+  colors:
+    Synced: green
+    OutOfSync: yellow
+`,
+			want: 2,
+		},
+		{
+			name:    "missing resource",
+			raw:     "- group: cert-manager.io\n  version: v1\n  condition_type: Ready\n",
+			wantErr: true,
+		},
+		{
+			name:    "missing state source",
+			raw:     "- group: cert-manager.io\n  version: v1\n  resource: certificates\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseCustomResources(tt.raw)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseCustomResources() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(got) != tt.want {
+				t.Errorf("ParseCustomResources() got %d definitions, want %d", len(got), tt.want)
+			}
+		})
+	}
+}
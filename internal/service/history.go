@@ -0,0 +1,287 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kubebadges/kubebadges/internal/k8s"
+	"github.com/kubebadges/kubebadges/internal/metrics"
+	"github.com/kubebadges/kubebadges/internal/model"
+)
+
+const (
+	// HistoryRetention is how far back the history reaches, and so the
+	// longest uptime window.
+	HistoryRetention = 90 * 24 * time.Hour
+
+	historyMaxChanges    = 1000
+	historyFlushInterval = time.Minute
+	// historyMaxBytes keeps the kubebadge-history ConfigMap well below the
+	// 1 MiB object size limit of the API server.
+	historyMaxBytes = 900 * 1024
+)
+
+// HistoryService records the color changes of every badge and keeps
+// them in the kubebadge-history ConfigMap, so uptime survives restarts
+// without an external database.
+type HistoryService struct {
+	kubeHelper *k8s.KubeHelper
+	interval   time.Duration
+
+	mu        sync.RWMutex
+	histories map[string][]model.StatusChange // key is the badge key
+	dirty     bool
+}
+
+func NewHistoryService(kubeHelper *k8s.KubeHelper, interval time.Duration) *HistoryService {
+	return &HistoryService{
+		kubeHelper: kubeHelper,
+		interval:   interval,
+		histories:  map[string][]model.StatusChange{},
+	}
+}
+
+// Run loads the stored history, then records the statuses every interval
+// and writes changes back at most once a minute.
+func (h *HistoryService) Run(statuses func() []model.BadgeStatus) {
+	h.load()
+
+	ticker := time.NewTicker(h.interval)
+	defer ticker.Stop()
+
+	lastFlush := time.Now()
+	for now := range ticker.C {
+		h.Record(statuses(), now)
+		if now.Sub(lastFlush) >= historyFlushInterval {
+			h.flush()
+			lastFlush = now
+		}
+	}
+}
+
+// Record appends an entry for every badge whose color differs from its last
+// entry, and prunes entries that fell out of the retention period.
+func (h *HistoryService) Record(statuses []model.BadgeStatus, now time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	seen := map[string]bool{}
+	for _, status := range statuses {
+		seen[status.Key] = true
+		changes := h.histories[status.Key]
+		if len(changes) > 0 && changes[len(changes)-1].Color == status.Color {
+			continue
+		}
+		h.histories[status.Key] = pruneHistory(append(changes, model.StatusChange{
+			Time:    now,
+			Color:   status.Color,
+			Message: status.Message,
+		}), now)
+		h.dirty = true
+	}
+
+	// badges that are gone keep their history until it expires
+	for key, changes := range h.histories {
+		if !seen[key] && now.Sub(changes[len(changes)-1].Time) > HistoryRetention {
+			delete(h.histories, key)
+			h.dirty = true
+		}
+	}
+}
+
+// pruneHistory drops entries older than the retention period, keeping the
+// last one before the cutoff since it holds the state at the cutoff.
+func pruneHistory(changes []model.StatusChange, now time.Time) []model.StatusChange {
+	cutoff := now.Add(-HistoryRetention)
+	first := 0
+	for first+1 < len(changes) && !changes[first+1].Time.After(cutoff) {
+		first++
+	}
+	if len(changes)-first > historyMaxChanges {
+		first = len(changes) - historyMaxChanges
+	}
+	return changes[first:]
+}
+
+// History returns the recorded changes of a badge key, oldest first.
+func (h *HistoryService) History(key string) []model.StatusChange {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return append([]model.StatusChange(nil), h.histories[key]...)
+}
+
+// Uptime returns the percentage of the window in which the badge was up.
+// The boolean is false when the window holds no known state.
+func (h *HistoryService) Uptime(key string, window time.Duration, now time.Time) (float64, bool) {
+	return Uptime(h.History(key), now.Add(-window), now)
+}
+
+// Uptime computes the share of up time between start and now. Red counts as
+// down and grey, which badges use for unknown or pending states, is left
+// out; every other color counts as up.
+func Uptime(changes []model.StatusChange, start, now time.Time) (float64, bool) {
+	var up, total time.Duration
+	for i, change := range changes {
+		from := change.Time
+		if from.Before(start) {
+			from = start
+		}
+		to := now
+		if i+1 < len(changes) {
+			to = changes[i+1].Time
+		}
+		if !to.After(from) {
+			continue
+		}
+
+		switch strings.ToLower(change.Color) {
+		case "lightgrey", "lightgray", "grey", "gray", "inactive":
+		case "red", "critical", "#e05d44":
+			total += to.Sub(from)
+		default:
+			up += to.Sub(from)
+			total += to.Sub(from)
+		}
+	}
+	if total == 0 {
+		return 0, false
+	}
+	return float64(up) / float64(total) * 100, true
+}
+
+// ParseWindow parses an uptime window such as "30d", "12h" or "90m".
+func ParseWindow(window string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(window, "d"); ok {
+		value, err := strconv.Atoi(days)
+		if err != nil || value <= 0 {
+			return 0, fmt.Errorf("invalid window %q", window)
+		}
+		return time.Duration(value) * 24 * time.Hour, nil
+	}
+	value, err := time.ParseDuration(window)
+	if err != nil || value <= 0 {
+		return 0, fmt.Errorf("invalid window %q", window)
+	}
+	return value, nil
+}
+
+// The history of a badge is stored as its key on the first line, followed
+// by one "unix-seconds color message" line per change.
+func encodeHistory(key string, changes []model.StatusChange) string {
+	var sb strings.Builder
+	sb.WriteString(key)
+	for _, change := range changes {
+		fmt.Fprintf(&sb, "\n%d %s %s", change.Time.Unix(), change.Color, strings.ReplaceAll(change.Message, "\n", " "))
+	}
+	return sb.String()
+}
+
+func decodeHistory(value string) (string, []model.StatusChange, error) {
+	lines := strings.Split(value, "\n")
+	if len(lines[0]) == 0 {
+		return "", nil, errors.New("missing key")
+	}
+
+	var changes []model.StatusChange
+	for _, line := range lines[1:] {
+		fields := strings.SplitN(line, " ", 3)
+		if len(fields) < 2 {
+			return "", nil, fmt.Errorf("invalid history line %q", line)
+		}
+		seconds, err := strconv.ParseInt(fields[0], 10, 64)
+		if err != nil {
+			return "", nil, err
+		}
+		change := model.StatusChange{Time: time.Unix(seconds, 0), Color: fields[1]}
+		if len(fields) == 3 {
+			change.Message = fields[2]
+		}
+		changes = append(changes, change)
+	}
+	return lines[0], changes, nil
+}
+
+func (h *HistoryService) load() {
+	configMap, err := h.kubeHelper.GetOrCreateHistory()
+	if err != nil {
+		slog.Error("failed to load badge history", "error", err)
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for name, value := range configMap.Data {
+		key, changes, err := decodeHistory(value)
+		if err != nil {
+			slog.Error("invalid badge history", "name", name, "error", err)
+			continue
+		}
+		h.histories[key] = changes
+	}
+}
+
+func (h *HistoryService) flush() {
+	h.mu.Lock()
+	if !h.dirty {
+		h.mu.Unlock()
+		return
+	}
+	if dropped := boundHistories(h.histories, h.kubeHelper.GenerateKubeName, historyMaxBytes); dropped > 0 {
+		slog.Warn("badge history too large, dropped the oldest changes", "dropped", dropped)
+	}
+	data := make(map[string]string, len(h.histories))
+	size := 0
+	for key, changes := range h.histories {
+		name := h.kubeHelper.GenerateKubeName(key)
+		data[name] = encodeHistory(key, changes)
+		size += len(name) + len(data[name])
+	}
+	h.dirty = false
+	h.mu.Unlock()
+
+	configMap, err := h.kubeHelper.GetOrCreateHistory()
+	if err == nil {
+		configMap.Data = data
+		_, err = h.kubeHelper.UpdateHistory(configMap)
+	}
+	metrics.ObserveHistoryFlush(size, err)
+	if err != nil {
+		slog.Error("failed to store badge history", "error", err)
+		h.mu.Lock()
+		h.dirty = true
+		h.mu.Unlock()
+	}
+}
+
+// boundHistories drops the oldest changes across all badges until the
+// encoded histories fit in maxBytes, and returns how many were dropped. The
+// last change of a badge holds its current state and is always kept.
+func boundHistories(histories map[string][]model.StatusChange, generateName func(string) string, maxBytes int) int {
+	size := 0
+	for key, changes := range histories {
+		size += len(generateName(key)) + len(encodeHistory(key, changes))
+	}
+
+	dropped := 0
+	for size > maxBytes {
+		oldest := ""
+		for key, changes := range histories {
+			if len(changes) > 1 && (len(oldest) == 0 || changes[0].Time.Before(histories[oldest][0].Time)) {
+				oldest = key
+			}
+		}
+		if len(oldest) == 0 {
+			break
+		}
+		changes := histories[oldest]
+		size -= len(encodeHistory(oldest, changes[:1])) - len(oldest)
+		histories[oldest] = changes[1:]
+		dropped++
+	}
+	return dropped
+}
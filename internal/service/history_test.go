@@ -0,0 +1,168 @@
+package service
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/kubebadges/kubebadges/internal/model"
+)
+
+func TestUptime(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	hoursAgo := func(h int) time.Time { return now.Add(-time.Duration(h) * time.Hour) }
+
+	tests := []struct {
+		name    string
+		changes []model.StatusChange
+		start   time.Time
+		want    float64
+		wantOK  bool
+	}{
+		{
+			name:   "no history",
+			start:  hoursAgo(24),
+			wantOK: false,
+		},
+		{
+			name:    "always green",
+			changes: []model.StatusChange{{Time: hoursAgo(48), Color: "green"}},
+			start:   hoursAgo(24),
+			want:    100,
+			wantOK:  true,
+		},
+		{
+			name: "red for a quarter",
+			changes: []model.StatusChange{
+				{Time: hoursAgo(48), Color: "green"},
+				{Time: hoursAgo(12), Color: "red"},
+				{Time: hoursAgo(6), Color: "yellow"},
+			},
+			start:  hoursAgo(24),
+			want:   75,
+			wantOK: true,
+		},
+		{
+			name: "grey is left out",
+			changes: []model.StatusChange{
+				{Time: hoursAgo(24), Color: "lightgrey"},
+				{Time: hoursAgo(12), Color: "green"},
+				{Time: hoursAgo(6), Color: "red"},
+			},
+			start:  hoursAgo(24),
+			want:   50,
+			wantOK: true,
+		},
+		{
+			name:    "history starts inside the window",
+			changes: []model.StatusChange{{Time: hoursAgo(1), Color: "red"}},
+			start:   hoursAgo(24),
+			want:    0,
+			wantOK:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Uptime(tt.changes, tt.start, now)
+			if ok != tt.wantOK || math.Abs(got-tt.want) > 0.001 {
+				t.Errorf("Uptime() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestParseWindow(t *testing.T) {
+	tests := []struct {
+		window  string
+		want    time.Duration
+		wantErr bool
+	}{
+		{window: "30d", want: 30 * 24 * time.Hour},
+		{window: "12h", want: 12 * time.Hour},
+		{window: "0d", wantErr: true},
+		{window: "month", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.window, func(t *testing.T) {
+			got, err := ParseWindow(tt.window)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseWindow() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseWindow() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHistoryEncoding(t *testing.T) {
+	changes := []model.StatusChange{
+		{Time: time.Unix(1_700_000_000, 0), Color: "green", Message: "3/3 Available"},
+		{Time: time.Unix(1_700_003_600, 0), Color: "red", Message: "0/3 Unavailable"},
+	}
+	key, decoded, err := decodeHistory(encodeHistory("/kube/deployment/shop/api", changes))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key != "/kube/deployment/shop/api" || !reflect.DeepEqual(decoded, changes) {
+		t.Errorf("decodeHistory() = %q, %+v", key, decoded)
+	}
+}
+
+func TestHistoryRecord(t *testing.T) {
+	history := NewHistoryService(nil, time.Minute)
+	now := time.Unix(1_700_000_000, 0)
+	status := func(color string) []model.BadgeStatus {
+		return []model.BadgeStatus{{Key: "/kube/job/shop/migrate", Color: color}}
+	}
+
+	history.Record(status("green"), now.Add(-100*24*time.Hour))
+	history.Record(status("green"), now.Add(-99*24*time.Hour))
+	history.Record(status("red"), now.Add(-95*24*time.Hour))
+	history.Record(status("green"), now)
+
+	changes := history.History("/kube/job/shop/migrate")
+	if len(changes) != 2 || changes[0].Color != "red" || changes[1].Color != "green" {
+		t.Errorf("expected the state at the retention cutoff and the latest change, got %+v", changes)
+	}
+}
+
+func TestBoundHistories(t *testing.T) {
+	name := func(key string) string { return key }
+	start := time.Unix(1_700_000_000, 0)
+	changes := func(offset time.Duration, n int) []model.StatusChange {
+		var result []model.StatusChange
+		for i := 0; i < n; i++ {
+			result = append(result, model.StatusChange{Time: start.Add(offset + time.Duration(i)*time.Hour), Color: "green", Message: "ok"})
+		}
+		return result
+	}
+	size := func(histories map[string][]model.StatusChange) int {
+		total := 0
+		for key, changes := range histories {
+			total += len(name(key)) + len(encodeHistory(key, changes))
+		}
+		return total
+	}
+
+	histories := map[string][]model.StatusChange{
+		"/probe/old": changes(0, 10),
+		"/probe/new": changes(100*time.Hour, 10),
+	}
+	limit := size(histories) - 1
+	if dropped := boundHistories(histories, name, limit); dropped != 1 {
+		t.Errorf("boundHistories() dropped %d, want 1", dropped)
+	}
+	if len(histories["/probe/old"]) != 9 || len(histories["/probe/new"]) != 10 {
+		t.Errorf("expected the oldest change to be dropped, got %d and %d", len(histories["/probe/old"]), len(histories["/probe/new"]))
+	}
+	if size(histories) > limit {
+		t.Errorf("size %d exceeds %d", size(histories), limit)
+	}
+
+	boundHistories(histories, name, 0)
+	if len(histories["/probe/old"]) != 1 || len(histories["/probe/new"]) != 1 {
+		t.Errorf("expected the last change of every badge to be kept, got %+v", histories)
+	}
+}
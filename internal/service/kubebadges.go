@@ -3,9 +3,13 @@ package service
 import (
 	"errors"
 	"log/slog"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/kubebadges/kubebadges/internal/config"
 	"github.com/kubebadges/kubebadges/internal/k8s"
+	"github.com/kubebadges/kubebadges/internal/metrics"
 	v1 "github.com/kubebadges/kubebadges/pkg/apis/kubebadges/v1"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/tools/cache"
@@ -29,8 +33,12 @@ type KubeBadgesService struct {
 	kubeHelper        *k8s.KubeHelper
 	informer          cache.SharedIndexInformer
 	queue             workqueue.RateLimitingInterface
-	cacheWithKey      *mcache.Cache[string, *v1.KubeBadge] // key is the kubebadge name
-	cacheWithAliasURL *mcache.Cache[string, *v1.KubeBadge] // key is the kubebadge's alias url
+	cacheWithKey      *mcache.Cache[string, *v1.KubeBadge]   // key is the kubebadge namespace/name
+	cacheWithAliasURL *mcache.Cache[string, *v1.KubeBadge]   // key is the kubebadge's alias url
+	messageTemplates  *mcache.Cache[string, messageTemplate] // key is the kubebadge namespace/name
+
+	handlersMu     sync.Mutex
+	changeHandlers []func()
 }
 
 func NewKubeBadgesService(kubeHelper *k8s.KubeHelper) *KubeBadgesService {
@@ -40,8 +48,11 @@ func NewKubeBadgesService(kubeHelper *k8s.KubeHelper) *KubeBadgesService {
 		queue:             workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter()),
 		cacheWithKey:      mcache.NewCache[string, *v1.KubeBadge](),
 		cacheWithAliasURL: mcache.NewCache[string, *v1.KubeBadge](),
+		messageTemplates:  mcache.NewCache[string, messageTemplate](),
 	}
 	service.init()
+	metrics.RegisterCache("kubebadges_by_name", service.cacheWithKey.Stats)
+	metrics.RegisterCache("kubebadges_by_alias", service.cacheWithAliasURL.Stats)
 
 	return service
 }
@@ -111,23 +122,153 @@ func (k *KubeBadgesService) processNextItem() bool {
 		} else if qe.action == eventActionDelete {
 			k.deleteKubeBadge(qe.kubebadge)
 		}
+
+		k.handlersMu.Lock()
+		for _, handler := range k.changeHandlers {
+			handler()
+		}
+		k.handlersMu.Unlock()
 	}
 
 	return true
 }
 
+// normalizeAlias strips the surrounding slashes so that "svc", "/svc" and
+// "/svc/" resolve to the same badge.
+func normalizeAlias(aliasURL string) string {
+	return strings.Trim(aliasURL, "/")
+}
+
+func cacheKey(namespace, name string) string {
+	return namespace + "/" + name
+}
+
 func (k *KubeBadgesService) addOrUpdateKubeBadge(kubebadge *v1.KubeBadge) {
-	k.cacheWithKey.Set(kubebadge.ObjectMeta.Name, kubebadge, 48*time.Hour)
-	if len(kubebadge.Spec.AliasURL) > 0 {
-		k.cacheWithAliasURL.Set(kubebadge.Spec.AliasURL, kubebadge, 48*time.Hour)
+	if !ownsBadge(kubebadge, k.GenerateKubeBadgeName) {
+		slog.Warn("ignoring kubebadge for a resource outside its namespace",
+			slog.String("namespace", kubebadge.Namespace), slog.String("name", kubebadge.Name), slog.String("key", kubebadge.Spec.OriginalURL))
+		k.deleteKubeBadge(kubebadge)
+		return
+	}
+
+	// drop the previous alias, otherwise a renamed alias keeps resolving
+	if old, ok := k.cacheWithKey.Get(cacheKey(kubebadge.Namespace, kubebadge.Name)); ok &&
+		normalizeAlias(old.Spec.AliasURL) != normalizeAlias(kubebadge.Spec.AliasURL) {
+		k.deleteAlias(old)
+	}
+
+	k.cacheWithKey.Set(cacheKey(kubebadge.Namespace, kubebadge.Name), kubebadge, 48*time.Hour)
+	k.loadMessageTemplate(kubebadge)
+	if alias := normalizeAlias(kubebadge.Spec.AliasURL); len(alias) > 0 {
+		if holder, ok := k.cacheWithAliasURL.Get(alias); ok && !canTakeAlias(kubebadge, holder) {
+			slog.Warn("ignoring alias held by another namespace", slog.String("alias", alias),
+				slog.String("namespace", kubebadge.Namespace), slog.String("name", kubebadge.Name), slog.String("holder", holder.Namespace))
+			return
+		}
+		k.cacheWithAliasURL.Set(alias, kubebadge, 48*time.Hour)
 	}
 }
 
+// canTakeAlias reports whether a KubeBadge may take an alias from its
+// current holder. The platform namespace can take any alias, while a team
+// cannot take one held by another namespace, or it could redirect the alias
+// URLs embedded in the READMEs of that team.
+func canTakeAlias(kubebadge, holder *v1.KubeBadge) bool {
+	return kubebadge.Namespace == config.KubeBadgeNamespace || holder.Namespace == kubebadge.Namespace
+}
+
 func (k *KubeBadgesService) deleteKubeBadge(kubebadge *v1.KubeBadge) {
-	k.cacheWithKey.Delete(kubebadge.ObjectMeta.Name)
-	if len(kubebadge.Spec.AliasURL) > 0 {
-		k.cacheWithAliasURL.Delete(kubebadge.Spec.AliasURL)
+	if old, ok := k.cacheWithKey.Get(cacheKey(kubebadge.Namespace, kubebadge.Name)); ok {
+		k.deleteAlias(old)
 	}
+	k.cacheWithKey.Delete(cacheKey(kubebadge.Namespace, kubebadge.Name))
+	k.messageTemplates.Delete(cacheKey(kubebadge.Namespace, kubebadge.Name))
+}
+
+// deleteAlias drops the alias of a KubeBadge, unless another KubeBadge holds
+// it. A KubeBadge that was kept from the alias takes it over, preferring the
+// platform namespace.
+func (k *KubeBadgesService) deleteAlias(kubebadge *v1.KubeBadge) {
+	alias := normalizeAlias(kubebadge.Spec.AliasURL)
+	holder, ok := k.cacheWithAliasURL.Get(alias)
+	if !ok || holder.Namespace != kubebadge.Namespace || holder.Name != kubebadge.Name {
+		return
+	}
+	k.cacheWithAliasURL.Delete(alias)
+	var next *v1.KubeBadge
+	for _, other := range k.ListKubeBadges() {
+		if normalizeAlias(other.Spec.AliasURL) != alias ||
+			(other.Namespace == kubebadge.Namespace && other.Name == kubebadge.Name) {
+			continue
+		}
+		if next == nil || other.Namespace == config.KubeBadgeNamespace {
+			next = other
+		}
+	}
+	if next != nil {
+		k.cacheWithAliasURL.Set(alias, next, 48*time.Hour)
+	}
+}
+
+// OnChange calls handler after a KubeBadge event has been applied to the
+// caches.
+func (k *KubeBadgesService) OnChange(handler func()) {
+	k.handlersMu.Lock()
+	defer k.handlersMu.Unlock()
+	k.changeHandlers = append(k.changeHandlers, handler)
+}
+
+// HasSynced reports whether the KubeBadge informer has completed its initial
+// list.
+func (k *KubeBadgesService) HasSynced() bool {
+	return k.informer.HasSynced()
+}
+
+// ListKubeBadges returns every KubeBadge known to the informer that governs
+// its badge, see GetKubeBadge. The objects are shared with the informer cache
+// and must not be modified.
+func (k *KubeBadgesService) ListKubeBadges() []*v1.KubeBadge {
+	store := k.informer.GetStore()
+	items := store.List()
+	result := make([]*v1.KubeBadge, 0, len(items))
+	for _, item := range items {
+		value, ok := item.(*v1.KubeBadge)
+		if !ok || !ownsBadge(value, k.GenerateKubeBadgeName) {
+			continue
+		}
+		if value.Namespace != config.KubeBadgeNamespace {
+			if _, shadowed, _ := store.GetByKey(cacheKey(config.KubeBadgeNamespace, value.Name)); shadowed {
+				continue
+			}
+		}
+		result = append(result, value)
+	}
+	return result
+}
+
+// ListAllKubeBadges returns every KubeBadge known to the informer, including
+// those that do not govern their badge. The objects are shared with the
+// informer cache and must not be modified.
+func (k *KubeBadgesService) ListAllKubeBadges() []*v1.KubeBadge {
+	items := k.informer.GetStore().List()
+	result := make([]*v1.KubeBadge, 0, len(items))
+	for _, item := range items {
+		if value, ok := item.(*v1.KubeBadge); ok {
+			result = append(result, value)
+		}
+	}
+	return result
+}
+
+// GetKubeBadgeIn returns a KubeBadge by namespace and name from the informer,
+// whether or not it governs its badge.
+func (k *KubeBadgesService) GetKubeBadgeIn(namespace, name string) (*v1.KubeBadge, bool) {
+	item, exists, err := k.informer.GetStore().GetByKey(cacheKey(namespace, name))
+	if err != nil || !exists {
+		return nil, false
+	}
+	kubeBadge, ok := item.(*v1.KubeBadge)
+	return kubeBadge, ok
 }
 
 func (k *KubeBadgesService) GenerateKubeBadgeName(name string) string {
@@ -146,10 +287,19 @@ func (k *KubeBadgesService) UpdateKubeBadge(kubeBadge *v1.KubeBadge) (*v1.KubeBa
 	return k.kubeHelper.UpdateKubeBadge(kubeBadge)
 }
 
+// GetKubeBadge returns the KubeBadge of a badge key. A KubeBadge in
+// config.KubeBadgeNamespace takes precedence over one in the namespace of
+// the badge's resource. With force, a cache miss is looked up in
+// config.KubeBadgeNamespace.
 func (k *KubeBadgesService) GetKubeBadge(name string, force bool) (*v1.KubeBadge, error) {
-	if result, ok := k.cacheWithKey.Get(k.GenerateKubeBadgeName(name)); ok {
+	if result, ok := k.cacheWithKey.Get(cacheKey(config.KubeBadgeNamespace, k.GenerateKubeBadgeName(name))); ok {
 		return result, nil
 	}
+	if namespace := KeyNamespace(name); len(namespace) > 0 && namespace != config.KubeBadgeNamespace {
+		if result, ok := k.cacheWithKey.Get(cacheKey(namespace, k.GenerateKubeBadgeName(name))); ok {
+			return result, nil
+		}
+	}
 	if !force {
 		return nil, errors.New("not found")
 	}
@@ -165,21 +315,12 @@ func (k *KubeBadgesService) GetKubeBadge(name string, force bool) (*v1.KubeBadge
 
 }
 
+// GetKubeBadgeByAlias returns the KubeBadge holding an alias. Aliases are
+// served on a public route, so they are only resolved from the informer's
+// caches and a miss never reaches the API server.
 func (k *KubeBadgesService) GetKubeBadgeByAlias(aliasURL string) (*v1.KubeBadge, error) {
-	if result, ok := k.cacheWithAliasURL.Get(aliasURL); ok {
+	if result, ok := k.cacheWithAliasURL.Get(normalizeAlias(aliasURL)); ok {
 		return result, nil
 	}
-
-	result, err := k.kubeHelper.GetBadgeByLabel(v1.KubeBadgeLabelAliasURL, k.GenerateKubeBadgeName(aliasURL))
-	if err != nil {
-		return nil, err
-	}
-	if len(result) == 0 {
-		return nil, errors.New("not found")
-	}
-
-	k.addOrUpdateKubeBadge(&result[0])
-
-	return &result[0], nil
-
+	return nil, errors.New("not found")
 }
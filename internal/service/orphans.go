@@ -0,0 +1,183 @@
+package service
+
+import (
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/kubebadges/kubebadges/internal/k8s"
+	"github.com/kubebadges/kubebadges/internal/model"
+	v1 "github.com/kubebadges/kubebadges/pkg/apis/kubebadges/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type orphanAction int
+
+const (
+	orphanKeep orphanAction = iota
+	orphanMark
+	orphanClear
+	orphanDelete
+)
+
+// OrphanService checks the resource behind every KubeBadge. A KubeBadge
+// whose resource is gone gets status.orphanedSince set, and is deleted once
+// it has been orphaned for the grace period. A grace period of zero keeps
+// orphans until they are deleted by hand.
+type OrphanService struct {
+	kubeHelper             *k8s.KubeHelper
+	kubeBadgesService      *KubeBadgesService
+	customResourcesService *CustomResourcesService
+	interval               time.Duration
+	gracePeriod            time.Duration
+}
+
+func NewOrphanService(kubeHelper *k8s.KubeHelper, kubeBadgesService *KubeBadgesService, customResourcesService *CustomResourcesService, interval, gracePeriod time.Duration) *OrphanService {
+	return &OrphanService{
+		kubeHelper:             kubeHelper,
+		kubeBadgesService:      kubeBadgesService,
+		customResourcesService: customResourcesService,
+		interval:               interval,
+		gracePeriod:            gracePeriod,
+	}
+}
+
+func (o *OrphanService) Run() {
+	ticker := time.NewTicker(o.interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		// a resource missing from an informer that is still listing is not gone
+		if !o.kubeBadgesService.HasSynced() || !o.kubeHelper.InformersSynced() {
+			continue
+		}
+		o.reconcile(time.Now())
+	}
+}
+
+func (o *OrphanService) reconcile(now time.Time) {
+	for _, kubeBadge := range o.kubeBadgesService.ListKubeBadges() {
+		exists, err := o.targetExists(kubeBadge.Spec.OriginalURL)
+		if err != nil {
+			slog.Warn("failed to check the resource of a kubebadge", slog.String("key", kubeBadge.Spec.OriginalURL), slog.String("error", err.Error()))
+			continue
+		}
+
+		switch decideOrphan(kubeBadge.Status, exists, now, o.gracePeriod) {
+		case orphanMark:
+			slog.Info("kubebadge orphaned", slog.String("key", kubeBadge.Spec.OriginalURL))
+			updated := kubeBadge.DeepCopy()
+			updated.Status.OrphanedSince = &metav1.Time{Time: now}
+			_, err = o.kubeHelper.UpdateKubeBadgeStatus(updated)
+		case orphanClear:
+			slog.Info("kubebadge resource is back", slog.String("key", kubeBadge.Spec.OriginalURL))
+			updated := kubeBadge.DeepCopy()
+			updated.Status.OrphanedSince = nil
+			_, err = o.kubeHelper.UpdateKubeBadgeStatus(updated)
+		case orphanDelete:
+			slog.Info("deleting orphaned kubebadge", slog.String("key", kubeBadge.Spec.OriginalURL))
+			err = o.kubeHelper.DeleteKubeBadgeIn(kubeBadge.Namespace, kubeBadge.Name)
+		}
+		if err != nil && !apierrors.IsNotFound(err) {
+			slog.Warn("failed to update orphaned kubebadge", slog.String("key", kubeBadge.Spec.OriginalURL), slog.String("error", err.Error()))
+		}
+	}
+}
+
+func decideOrphan(status v1.KubeBadgeStatus, exists bool, now time.Time, gracePeriod time.Duration) orphanAction {
+	switch {
+	case exists && status.OrphanedSince != nil:
+		return orphanClear
+	case exists:
+		return orphanKeep
+	case status.OrphanedSince == nil:
+		return orphanMark
+	case gracePeriod > 0 && !now.Before(status.OrphanedSince.Add(gracePeriod)):
+		return orphanDelete
+	}
+	return orphanKeep
+}
+
+// Orphans returns the KubeBadges currently marked as orphaned.
+func (o *OrphanService) Orphans() []model.OrphanedBadge {
+	result := []model.OrphanedBadge{}
+	for _, kubeBadge := range o.kubeBadgesService.ListKubeBadges() {
+		since := kubeBadge.Status.OrphanedSince
+		if since == nil {
+			continue
+		}
+		orphan := model.OrphanedBadge{
+			Key:           kubeBadge.Spec.OriginalURL,
+			Namespace:     kubeBadge.Namespace,
+			Name:          kubeBadge.Name,
+			Allowed:       kubeBadge.Spec.Allowed,
+			OrphanedSince: since.Time,
+		}
+		if o.gracePeriod > 0 {
+			deleteAt := since.Add(o.gracePeriod)
+			orphan.DeleteAt = &deleteAt
+		}
+		result = append(result, orphan)
+	}
+	return result
+}
+
+// targetExists reports whether the resource a badge key refers to exists.
+// Probe and group badges have no single resource and always exist.
+func (o *OrphanService) targetExists(key string) (bool, error) {
+	segments := strings.Split(strings.Trim(key, "/"), "/")
+	if len(segments) < 2 || segments[0] != "kube" {
+		return true, nil
+	}
+
+	var err error
+	switch kind := segments[1]; {
+	case kind == "node" && len(segments) == 3:
+		_, err = o.kubeHelper.GetNode(segments[2])
+	case (kind == "namespace" || kind == "summary") && len(segments) == 3:
+		_, err = o.kubeHelper.GetNamespace(segments[2])
+	case kind == "custom" && len(segments) == 6:
+		definition, definitionErr := o.customResourcesService.GetDefinition(segments[2], segments[3])
+		if definitionErr != nil {
+			// without its definition the badge cannot be served either way
+			return false, definitionErr
+		}
+		_, err = o.kubeHelper.GetResource(definition.GVR(), segments[4], segments[5])
+	case len(segments) == 4:
+		err = o.getWorkload(kind, segments[2], segments[3])
+	default:
+		return false, errors.New("unknown badge key")
+	}
+
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (o *OrphanService) getWorkload(kind, namespace, name string) error {
+	var err error
+	switch kind {
+	case "deployment":
+		_, err = o.kubeHelper.GetDeployment(namespace, name)
+	case "statefulset":
+		_, err = o.kubeHelper.GetStatefulSet(namespace, name)
+	case "daemonset":
+		_, err = o.kubeHelper.GetDaemonSet(namespace, name)
+	case "pod":
+		_, err = o.kubeHelper.GetPod(namespace, name)
+	case "job":
+		_, err = o.kubeHelper.GetJob(namespace, name)
+	case "cronjob":
+		_, err = o.kubeHelper.GetCronJob(namespace, name)
+	case "kustomization":
+		_, err = o.kubeHelper.GetKustomization(namespace, name)
+	case "postgresql":
+		_, err = o.kubeHelper.GetPostgresql(namespace, name)
+	default:
+		err = errors.New("unknown badge kind " + kind)
+	}
+	return err
+}
@@ -0,0 +1,38 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	v1 "github.com/kubebadges/kubebadges/pkg/apis/kubebadges/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestDecideOrphan(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	orphaned := func(ago time.Duration) v1.KubeBadgeStatus {
+		return v1.KubeBadgeStatus{OrphanedSince: &metav1.Time{Time: now.Add(-ago)}}
+	}
+
+	tests := []struct {
+		name        string
+		status      v1.KubeBadgeStatus
+		exists      bool
+		gracePeriod time.Duration
+		want        orphanAction
+	}{
+		{"exists", v1.KubeBadgeStatus{}, true, 0, orphanKeep},
+		{"came back", orphaned(time.Hour), true, 0, orphanClear},
+		{"newly orphaned", v1.KubeBadgeStatus{}, false, time.Hour, orphanMark},
+		{"within grace period", orphaned(30 * time.Minute), false, time.Hour, orphanKeep},
+		{"grace period over", orphaned(time.Hour), false, time.Hour, orphanDelete},
+		{"kept without grace period", orphaned(24 * time.Hour), false, 0, orphanKeep},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := decideOrphan(tt.status, tt.exists, now, tt.gracePeriod); got != tt.want {
+				t.Errorf("decideOrphan() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package service
+
+import (
+	"strings"
+
+	"github.com/kubebadges/kubebadges/internal/config"
+	v1 "github.com/kubebadges/kubebadges/pkg/apis/kubebadges/v1"
+)
+
+// KeyNamespace returns the namespace of the resource a badge key refers to,
+// or "" for badges that are not namespaced, such as node, probe and group
+// badges.
+func KeyNamespace(key string) string {
+	segments := strings.Split(strings.Trim(key, "/"), "/")
+	switch {
+	case len(segments) == 3 && segments[0] == "kube" && (segments[1] == "namespace" || segments[1] == "summary"):
+		return segments[2]
+	case len(segments) == 4 && segments[0] == "kube" && segments[1] != "node":
+		return segments[2]
+	case len(segments) == 6 && segments[0] == "kube" && segments[1] == "custom":
+		return segments[4]
+	}
+	return ""
+}
+
+// NamespaceOwns reports whether a KubeBadge with spec may govern its badge
+// from namespace. config.KubeBadgeNamespace governs every badge. Any other
+// namespace only governs badges of its own resources, so that teams manage
+// their badges without reaching into other namespaces.
+func NamespaceOwns(namespace string, spec v1.KubeBadgeSpec) bool {
+	if namespace == config.KubeBadgeNamespace {
+		return true
+	}
+	if KeyNamespace(spec.OriginalURL) != namespace {
+		return false
+	}
+	return len(spec.OwnerNamespace) == 0 || spec.OwnerNamespace == namespace
+}
+
+// ownsBadge reports whether a KubeBadge may govern its badge, see
+// NamespaceOwns.
+func ownsBadge(kubeBadge *v1.KubeBadge, generateName func(string) string) bool {
+	if !NamespaceOwns(kubeBadge.Namespace, kubeBadge.Spec) {
+		return false
+	}
+	if kubeBadge.Namespace == config.KubeBadgeNamespace {
+		return true
+	}
+	// badges are looked up by the name derived from their key
+	return kubeBadge.Name == generateName(kubeBadge.Spec.OriginalURL)
+}
@@ -0,0 +1,219 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/kubebadges/kubebadges/internal/k8s"
+	v1 "github.com/kubebadges/kubebadges/pkg/apis/kubebadges/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+
+	mcache "github.com/kubebadges/kubebadges/internal/cache"
+)
+
+func TestKeyNamespace(t *testing.T) {
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{"/kube/deployment/shop/api", "shop"},
+		{"/kube/cronjob/shop/report", "shop"},
+		{"/kube/namespace/shop", "shop"},
+		{"/kube/summary/shop", "shop"},
+		{"/kube/custom/apps/deployments/shop/api", "shop"},
+		{"/kube/node/worker-1", ""},
+		{"/probe/api", ""},
+		{"/group/checkout", ""},
+		{"/kube/deployment/shop", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			if got := KeyNamespace(tt.key); got != tt.want {
+				t.Errorf("KeyNamespace() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func newTestKubeBadge(namespace, key, alias string) *v1.KubeBadge {
+	helper := &k8s.KubeHelper{}
+	return &v1.KubeBadge{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: helper.GenerateKubeName(key)},
+		Spec:       v1.KubeBadgeSpec{OriginalURL: key, AliasURL: alias},
+	}
+}
+
+func TestOwnsBadge(t *testing.T) {
+	helper := &k8s.KubeHelper{}
+	renamed := newTestKubeBadge("shop", "/kube/deployment/shop/api", "")
+	renamed.Name = "kube-deployment-billing-api"
+	foreignOwner := newTestKubeBadge("shop", "/kube/deployment/shop/api", "")
+	foreignOwner.Spec.OwnerNamespace = "billing"
+
+	tests := []struct {
+		name      string
+		kubeBadge *v1.KubeBadge
+		want      bool
+	}{
+		{"platform namespace", newTestKubeBadge("kubebadges", "/kube/deployment/billing/api", ""), true},
+		{"platform namespace node", newTestKubeBadge("kubebadges", "/kube/node/worker-1", ""), true},
+		{"own namespace", newTestKubeBadge("shop", "/kube/deployment/shop/api", ""), true},
+		{"own namespace summary", newTestKubeBadge("shop", "/kube/summary/shop", ""), true},
+		{"other namespace", newTestKubeBadge("shop", "/kube/deployment/billing/api", ""), false},
+		{"node", newTestKubeBadge("shop", "/kube/node/worker-1", ""), false},
+		{"probe", newTestKubeBadge("shop", "/probe/api", ""), false},
+		{"name of another key", renamed, false},
+		{"other owner namespace", foreignOwner, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ownsBadge(tt.kubeBadge, helper.GenerateKubeName); got != tt.want {
+				t.Errorf("ownsBadge() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func newTestKubeBadgesService(kubeBadges ...*v1.KubeBadge) *KubeBadgesService {
+	service := &KubeBadgesService{
+		kubeHelper:        &k8s.KubeHelper{},
+		informer:          cache.NewSharedIndexInformer(&cache.ListWatch{}, &v1.KubeBadge{}, 0, cache.Indexers{}),
+		cacheWithKey:      mcache.NewCache[string, *v1.KubeBadge](),
+		cacheWithAliasURL: mcache.NewCache[string, *v1.KubeBadge](),
+		messageTemplates:  mcache.NewCache[string, messageTemplate](),
+	}
+	for _, kubeBadge := range kubeBadges {
+		_ = service.informer.GetStore().Add(kubeBadge)
+		service.addOrUpdateKubeBadge(kubeBadge)
+	}
+	return service
+}
+
+func TestNamespaceOwns(t *testing.T) {
+	tests := []struct {
+		name      string
+		namespace string
+		spec      v1.KubeBadgeSpec
+		want      bool
+	}{
+		{"platform namespace", "kubebadges", v1.KubeBadgeSpec{OriginalURL: "/kube/deployment/billing/api"}, true},
+		{"own resource", "shop", v1.KubeBadgeSpec{OriginalURL: "/kube/deployment/shop/api", OwnerNamespace: "shop"}, true},
+		{"other namespace", "shop", v1.KubeBadgeSpec{OriginalURL: "/kube/deployment/billing/api"}, false},
+		{"other owner", "shop", v1.KubeBadgeSpec{OriginalURL: "/kube/deployment/shop/api", OwnerNamespace: "billing"}, false},
+		{"probe", "shop", v1.KubeBadgeSpec{OriginalURL: "/probe/api"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NamespaceOwns(tt.namespace, tt.spec); got != tt.want {
+				t.Errorf("NamespaceOwns() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetKubeBadgeOwnership(t *testing.T) {
+	platform := newTestKubeBadge("kubebadges", "/kube/deployment/shop/api", "api")
+	tenant := newTestKubeBadge("shop", "/kube/deployment/shop/api", "api")
+	tenantOnly := newTestKubeBadge("shop", "/kube/deployment/shop/web", "web")
+	intruder := newTestKubeBadge("shop", "/kube/deployment/billing/api", "billing")
+	service := newTestKubeBadgesService(platform, tenant, tenantOnly, intruder)
+
+	tests := []struct {
+		key           string
+		wantNamespace string
+	}{
+		{"/kube/deployment/shop/api", "kubebadges"},
+		{"/kube/deployment/shop/web", "shop"},
+		{"/kube/deployment/billing/api", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			kubeBadge, err := service.GetKubeBadge(tt.key, false)
+			got := ""
+			if err == nil {
+				got = kubeBadge.Namespace
+			}
+			if got != tt.wantNamespace {
+				t.Errorf("GetKubeBadge() namespace = %q, want %q", got, tt.wantNamespace)
+			}
+		})
+	}
+
+	if got := len(service.ListKubeBadges()); got != 2 {
+		t.Errorf("ListKubeBadges() returned %d badges, want 2", got)
+	}
+	if _, ok := service.cacheWithAliasURL.Get("billing"); ok {
+		t.Errorf("the alias of a badge outside its namespace was cached")
+	}
+
+	// the tenant takes over once the platform badge is gone
+	_ = service.informer.GetStore().Delete(platform)
+	service.deleteKubeBadge(platform)
+	if kubeBadge, err := service.GetKubeBadge("/kube/deployment/shop/api", false); err != nil || kubeBadge.Namespace != "shop" {
+		t.Errorf("GetKubeBadge() after delete = %v, %v, want the shop badge", kubeBadge, err)
+	}
+	if kubeBadge, err := service.GetKubeBadgeByAlias("api"); err != nil || kubeBadge.Namespace != "shop" {
+		t.Errorf("GetKubeBadgeByAlias() after delete = %v, %v, want the shop badge", kubeBadge, err)
+	}
+}
+
+func TestAliasAcrossNamespaces(t *testing.T) {
+	first := newTestKubeBadge("shop", "/kube/deployment/shop/api", "checkout")
+	second := newTestKubeBadge("billing", "/kube/deployment/billing/api", "checkout")
+	service := newTestKubeBadgesService(first, second)
+
+	if holder, ok := service.cacheWithAliasURL.Get("checkout"); !ok || holder.Namespace != "shop" {
+		t.Fatalf("alias holder = %v, want the first team to keep it", holder)
+	}
+
+	// the platform namespace can take any alias
+	platform := newTestKubeBadge("kubebadges", "/kube/deployment/billing/web", "checkout")
+	_ = service.informer.GetStore().Add(platform)
+	service.addOrUpdateKubeBadge(platform)
+	if holder, _ := service.cacheWithAliasURL.Get("checkout"); holder.Namespace != "kubebadges" {
+		t.Fatalf("alias holder = %v, want the platform badge", holder)
+	}
+
+	// once the holder is gone, a waiting badge takes the alias over
+	for _, kubeBadge := range []*v1.KubeBadge{platform, first} {
+		_ = service.informer.GetStore().Delete(kubeBadge)
+		service.deleteKubeBadge(kubeBadge)
+	}
+	if holder, ok := service.cacheWithAliasURL.Get("checkout"); !ok || holder.Namespace != "billing" {
+		t.Errorf("alias holder = %v, want the billing badge", holder)
+	}
+}
+
+func TestGetKubeBadgeByAliasMiss(t *testing.T) {
+	// the test service has no API client, so a lookup past the caches panics
+	service := newTestKubeBadgesService(newTestKubeBadge("kubebadges", "/kube/deployment/shop/api", "api"))
+	for _, alias := range []string{"/api/", "unknown", ""} {
+		kubeBadge, err := service.GetKubeBadgeByAlias(alias)
+		if found := err == nil; found != (alias == "/api/") {
+			t.Errorf("GetKubeBadgeByAlias(%q) = %v, %v", alias, kubeBadge, err)
+		}
+	}
+}
+
+func TestMessageTemplate(t *testing.T) {
+	valid := newTestKubeBadge("kubebadges", "/kube/deployment/shop/api", "")
+	valid.Spec.Appearance.MessageTemplate = "{{.Message}} on prod"
+	invalid := newTestKubeBadge("kubebadges", "/kube/deployment/shop/web", "")
+	invalid.Spec.Appearance.MessageTemplate = "{{.Missing}}"
+	service := newTestKubeBadgesService(valid, invalid)
+
+	first := service.MessageTemplate(valid)
+	if first == nil || service.MessageTemplate(valid) != first {
+		t.Fatalf("MessageTemplate() = %v, want the template parsed when the badge was loaded", first)
+	}
+	if service.MessageTemplate(invalid) != nil {
+		t.Errorf("MessageTemplate() of an invalid template is not nil")
+	}
+
+	changed := valid.DeepCopy()
+	changed.Spec.Appearance.MessageTemplate = "{{.Message}} on staging"
+	service.addOrUpdateKubeBadge(changed)
+	if got := service.MessageTemplate(changed); got == nil || got == first {
+		t.Errorf("MessageTemplate() after a change = %v, want a new template", got)
+	}
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kubebadges/kubebadges/internal/config"
+	"github.com/kubebadges/kubebadges/internal/probe"
+	v1 "github.com/kubebadges/kubebadges/pkg/apis/kubebadges/v1"
+)
+
+// ProbeService periodically probes the targets of probe badges whose
+// spec.custom.type names a supported probe, and keeps the latest result per
+// badge in memory.
+type ProbeService struct {
+	kubeBadgesService *KubeBadgesService
+
+	mu      sync.RWMutex
+	results map[string]probe.Result // key is the kubebadge name
+	nextRun map[string]time.Time
+	running map[string]bool
+}
+
+func NewProbeService(kubeBadgesService *KubeBadgesService) *ProbeService {
+	return &ProbeService{
+		kubeBadgesService: kubeBadgesService,
+		results:           map[string]probe.Result{},
+		nextRun:           map[string]time.Time{},
+		running:           map[string]bool{},
+	}
+}
+
+func (p *ProbeService) Run() {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		p.schedule(time.Now())
+	}
+}
+
+// schedule starts the probes that are due and forgets badges that no longer
+// exist or no longer define a probe.
+func (p *ProbeService) schedule(now time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	active := map[string]bool{}
+	for _, kubeBadge := range p.kubeBadgesService.ListKubeBadges() {
+		custom := kubeBadge.Spec.Custom
+		if !isProbeBadge(kubeBadge) || !probe.Supported(custom.Type) {
+			continue
+		}
+		name := kubeBadge.Name
+		active[name] = true
+		if p.running[name] || now.Before(p.nextRun[name]) {
+			continue
+		}
+
+		p.running[name] = true
+		p.nextRun[name] = now.Add(probe.Interval(custom))
+		go func() {
+			result := probe.Run(context.Background(), custom)
+			if !result.Up {
+				slog.Info("probe failed", "name", name, "type", custom.Type, "error", result.Error)
+			}
+
+			p.mu.Lock()
+			defer p.mu.Unlock()
+			p.running[name] = false
+			p.results[name] = result
+		}()
+	}
+
+	for name := range p.nextRun {
+		if !active[name] {
+			delete(p.nextRun, name)
+			delete(p.results, name)
+		}
+	}
+}
+
+// isProbeBadge reports whether a KubeBadge is a /probe/<name> badge of
+// config.KubeBadgeNamespace. Only those are probed: teams can create
+// KubeBadges in their own namespaces, and must not make the server send
+// requests to addresses of their choosing.
+func isProbeBadge(kubeBadge *v1.KubeBadge) bool {
+	if kubeBadge.Namespace != config.KubeBadgeNamespace {
+		return false
+	}
+	segments := strings.Split(strings.Trim(kubeBadge.Spec.OriginalURL, "/"), "/")
+	return len(segments) == 2 && segments[0] == "probe" && len(segments[1]) > 0
+}
+
+// GetResult returns the latest probe result of the KubeBadge with the given
+// name. The boolean is false until the first probe has finished.
+func (p *ProbeService) GetResult(name string) (probe.Result, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	result, ok := p.results[name]
+	return result, ok
+}
@@ -0,0 +1,52 @@
+package service
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kubebadges/kubebadges/internal/probe"
+	v1 "github.com/kubebadges/kubebadges/pkg/apis/kubebadges/v1"
+)
+
+func TestProbeServiceSchedule(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+	}))
+	defer server.Close()
+
+	withProbe := func(kubeBadge *v1.KubeBadge) *v1.KubeBadge {
+		kubeBadge.Spec.Custom = v1.Custom{Type: probe.TypeHTTP, Address: server.URL}
+		return kubeBadge
+	}
+	platform := withProbe(newTestKubeBadge("kubebadges", "/probe/checkout", ""))
+	tenant := withProbe(newTestKubeBadge("shop", "/kube/deployment/shop/api", ""))
+	notProbe := withProbe(newTestKubeBadge("kubebadges", "/kube/deployment/shop/web", ""))
+	probes := NewProbeService(newTestKubeBadgesService(platform, tenant, notProbe))
+
+	probes.schedule(time.Now())
+	probes.mu.RLock()
+	scheduled := len(probes.nextRun)
+	_, ok := probes.nextRun[platform.Name]
+	probes.mu.RUnlock()
+	if scheduled != 1 || !ok {
+		t.Fatalf("scheduled %d probes, want only %s", scheduled, platform.Name)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, ok := probes.GetResult(platform.Name); ok {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("the probe badge was not probed")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := requests.Load(); got != 1 {
+		t.Errorf("server received %d requests, want 1", got)
+	}
+}
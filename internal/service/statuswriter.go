@@ -0,0 +1,86 @@
+package service
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/kubebadges/kubebadges/internal/k8s"
+	"github.com/kubebadges/kubebadges/internal/model"
+	v1 "github.com/kubebadges/kubebadges/pkg/apis/kubebadges/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/flowcontrol"
+)
+
+// StatusWriter writes the computed state of every badge to the status of its
+// KubeBadge, so that kubectl shows whether a badge is green or red. Writes
+// are rate limited, and states that were not written are retried on the
+// next run.
+type StatusWriter struct {
+	kubeHelper        *k8s.KubeHelper
+	kubeBadgesService *KubeBadgesService
+	interval          time.Duration
+	limiter           flowcontrol.RateLimiter
+}
+
+func NewStatusWriter(kubeHelper *k8s.KubeHelper, kubeBadgesService *KubeBadgesService, interval time.Duration, writesPerSecond int) *StatusWriter {
+	return &StatusWriter{
+		kubeHelper:        kubeHelper,
+		kubeBadgesService: kubeBadgesService,
+		interval:          interval,
+		limiter:           flowcontrol.NewTokenBucketRateLimiter(float32(writesPerSecond), writesPerSecond),
+	}
+}
+
+// Run writes the statuses every interval. The statuses must include badges
+// that are not allowed.
+func (w *StatusWriter) Run(statuses func() []model.BadgeStatus) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		w.write(statuses(), time.Now())
+	}
+}
+
+func (w *StatusWriter) write(statuses []model.BadgeStatus, now time.Time) {
+	for _, status := range statuses {
+		kubeBadge, err := w.kubeBadgesService.GetKubeBadge(status.Key, false)
+		if err != nil {
+			continue
+		}
+		observed, changed := observeStatus(kubeBadge, status, now)
+		if !changed {
+			continue
+		}
+		if !w.limiter.TryAccept() {
+			return
+		}
+
+		updated := kubeBadge.DeepCopy()
+		updated.Status = observed
+		if _, err := w.kubeHelper.UpdateKubeBadgeStatus(updated); err != nil {
+			slog.Warn("failed to write kubebadge status", slog.String("key", status.Key), slog.String("error", err.Error()))
+		}
+	}
+}
+
+// observeStatus returns the status of a KubeBadge updated with a computed
+// state, and whether it differs from the current one.
+func observeStatus(kubeBadge *v1.KubeBadge, status model.BadgeStatus, now time.Time) (v1.KubeBadgeStatus, bool) {
+	current := kubeBadge.Status
+	observed := *current.DeepCopy()
+	observed.ObservedGeneration = kubeBadge.Generation
+	observed.Message = status.Message
+	observed.Color = status.Color
+	observed.LastError = status.Error
+	if observed.Color != current.Color || observed.LastTransitionTime == nil {
+		observed.LastTransitionTime = &metav1.Time{Time: now}
+	}
+
+	changed := observed.ObservedGeneration != current.ObservedGeneration ||
+		observed.Message != current.Message ||
+		observed.Color != current.Color ||
+		observed.LastError != current.LastError ||
+		!observed.LastTransitionTime.Equal(current.LastTransitionTime)
+	return observed, changed
+}
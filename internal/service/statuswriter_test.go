@@ -0,0 +1,47 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kubebadges/kubebadges/internal/model"
+	v1 "github.com/kubebadges/kubebadges/pkg/apis/kubebadges/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestObserveStatus(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	earlier := &metav1.Time{Time: now.Add(-time.Hour)}
+	green := v1.KubeBadgeStatus{ObservedGeneration: 2, Message: "3/3", Color: "green", LastTransitionTime: earlier}
+
+	tests := []struct {
+		name           string
+		generation     int64
+		current        v1.KubeBadgeStatus
+		status         model.BadgeStatus
+		wantChanged    bool
+		wantTransition *metav1.Time
+	}{
+		{"unchanged", 2, green, model.BadgeStatus{Message: "3/3", Color: "green"}, false, earlier},
+		{"new message", 2, green, model.BadgeStatus{Message: "4/4", Color: "green"}, true, earlier},
+		{"new color", 2, green, model.BadgeStatus{Message: "2/3", Color: "yellow"}, true, &metav1.Time{Time: now}},
+		{"new generation", 3, green, model.BadgeStatus{Message: "3/3", Color: "green"}, true, earlier},
+		{"error", 2, green, model.BadgeStatus{Message: "3/3", Color: "green", Error: "timeout"}, true, earlier},
+		{"first state", 1, v1.KubeBadgeStatus{}, model.BadgeStatus{Message: "3/3", Color: "green"}, true, &metav1.Time{Time: now}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kubeBadge := &v1.KubeBadge{ObjectMeta: metav1.ObjectMeta{Generation: tt.generation}, Status: tt.current}
+			observed, changed := observeStatus(kubeBadge, tt.status, now)
+			if changed != tt.wantChanged {
+				t.Errorf("observeStatus() changed = %v, want %v", changed, tt.wantChanged)
+			}
+			if !observed.LastTransitionTime.Equal(tt.wantTransition) {
+				t.Errorf("observeStatus() lastTransitionTime = %v, want %v", observed.LastTransitionTime, tt.wantTransition)
+			}
+			if observed.Message != tt.status.Message || observed.Color != tt.status.Color || observed.LastError != tt.status.Error {
+				t.Errorf("observeStatus() = %+v, want the state of %+v", observed, tt.status)
+			}
+		})
+	}
+}
@@ -0,0 +1,189 @@
+package service
+
+import (
+	"sync"
+	"time"
+
+	"github.com/kubebadges/kubebadges/internal/model"
+)
+
+const (
+	// streamDebounce coalesces the bursts of informer events, such as a
+	// rollout touching many pods, into one evaluation.
+	streamDebounce = time.Second
+	// streamResync re-evaluates without an event, for states that change
+	// with time such as probe results or the age of a CronJob run.
+	streamResync = 15 * time.Second
+
+	streamBuffer = 64
+)
+
+// StreamEvent is the state of a badge as pushed to stream subscribers. A
+// removed event only carries the key of a badge the subscriber no longer
+// sees, because it is gone or no longer allowed.
+type StreamEvent struct {
+	Key     string `json:"key"`
+	Label   string `json:"label"`
+	Message string `json:"message"`
+	Color   string `json:"color"`
+	Removed bool   `json:"-"`
+
+	allowed bool
+}
+
+// Subscription receives the events of a stream subscriber.
+type Subscription struct {
+	Events      <-chan StreamEvent
+	events      chan StreamEvent
+	allowedOnly bool
+}
+
+// StreamService re-evaluates the badges whenever the informers of
+// KubeHelper or KubeBadgesService report a change, and pushes the badges
+// whose state changed to the subscribers.
+type StreamService struct {
+	trigger chan struct{}
+
+	mu          sync.Mutex
+	states      map[string]StreamEvent // key is the badge key
+	subscribers map[*Subscription]bool
+}
+
+func NewStreamService(kubeHelper changeNotifier, kubeBadgesService changeNotifier) *StreamService {
+	stream := &StreamService{
+		trigger:     make(chan struct{}, 1),
+		states:      map[string]StreamEvent{},
+		subscribers: map[*Subscription]bool{},
+	}
+	kubeHelper.OnChange(stream.Notify)
+	kubeBadgesService.OnChange(stream.Notify)
+	return stream
+}
+
+type changeNotifier interface {
+	OnChange(handler func())
+}
+
+// Notify schedules an evaluation. It never blocks, so it is safe to call
+// from informer event handlers.
+func (s *StreamService) Notify() {
+	select {
+	case s.trigger <- struct{}{}:
+	default:
+	}
+}
+
+// Run evaluates the statuses after every change notification and at least
+// every streamResync, as long as there are subscribers. The statuses must
+// include badges that are not allowed, with model.BadgeStatus.Allowed set.
+func (s *StreamService) Run(statuses func() []model.BadgeStatus) {
+	ticker := time.NewTicker(streamResync)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.trigger:
+			time.Sleep(streamDebounce)
+		case <-ticker.C:
+		}
+		if s.active() {
+			s.Publish(statuses())
+		}
+	}
+}
+
+func (s *StreamService) active() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.subscribers) > 0
+}
+
+// Publish records the statuses and sends the ones that changed. Badges that
+// are gone are sent as removed.
+func (s *StreamService) Publish(statuses []model.BadgeStatus) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	seen := map[string]bool{}
+	for _, status := range statuses {
+		seen[status.Key] = true
+		event := StreamEvent{
+			Key:     status.Key,
+			Label:   status.Label,
+			Message: status.Message,
+			Color:   status.Color,
+			allowed: status.Allowed,
+		}
+		previous, ok := s.states[status.Key]
+		if ok && previous == event {
+			continue
+		}
+		s.states[status.Key] = event
+		for subscription := range s.subscribers {
+			s.send(subscription, event, previous.allowed)
+		}
+	}
+
+	for key, previous := range s.states {
+		if !seen[key] {
+			delete(s.states, key)
+			for subscription := range s.subscribers {
+				s.send(subscription, StreamEvent{Key: key, Removed: true}, previous.allowed)
+			}
+		}
+	}
+}
+
+// Subscribe returns a subscription that first receives the current state of
+// every badge. With allowedOnly, badges that are not allowed are left out.
+func (s *StreamService) Subscribe(allowedOnly bool) *Subscription {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	events := make(chan StreamEvent, streamBuffer+len(s.states))
+	subscription := &Subscription{Events: events, events: events, allowedOnly: allowedOnly}
+	for _, event := range s.states {
+		if event.allowed || !allowedOnly {
+			events <- event
+		}
+	}
+	s.subscribers[subscription] = true
+	s.Notify()
+	return subscription
+}
+
+// Unsubscribe stops the events of a subscription and closes its channel.
+func (s *StreamService) Unsubscribe(subscription *Subscription) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.remove(subscription)
+}
+
+// send delivers an event without blocking. A subscriber that fell behind is
+// dropped, and its client reconnects to start over from the current state.
+// A badge that stops being allowed is sent to allowedOnly subscribers as
+// removed, so they drop its last public state.
+func (s *StreamService) send(subscription *Subscription, event StreamEvent, wasAllowed bool) {
+	if subscription.allowedOnly && !event.allowed {
+		if !wasAllowed {
+			return
+		}
+		event = StreamEvent{Key: event.Key, Removed: true}
+	}
+	select {
+	case subscription.events <- event:
+	default:
+		s.remove(subscription)
+	}
+}
+
+func (s *StreamService) remove(subscription *Subscription) {
+	if s.subscribers[subscription] {
+		delete(s.subscribers, subscription)
+		close(subscription.events)
+	}
+	// without subscribers nothing is evaluated, so the states would go stale
+	if len(s.subscribers) == 0 {
+		s.states = map[string]StreamEvent{}
+	}
+}
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/kubebadges/kubebadges/internal/model"
+)
+
+type fakeNotifier struct{}
+
+func (fakeNotifier) OnChange(func()) {}
+
+func receive(subscription *Subscription) []StreamEvent {
+	var events []StreamEvent
+	for {
+		select {
+		case event := <-subscription.Events:
+			events = append(events, event)
+		default:
+			return events
+		}
+	}
+}
+
+func TestStreamService(t *testing.T) {
+	stream := NewStreamService(fakeNotifier{}, fakeNotifier{})
+	all := stream.Subscribe(false)
+	allowed := stream.Subscribe(true)
+
+	stream.Publish([]model.BadgeStatus{
+		{Key: "/kube/deployment/shop/api", Label: "api", Message: "1/1 Available", Color: "green", Allowed: true},
+		{Key: "/kube/deployment/shop/admin", Label: "admin", Message: "1/1 Available", Color: "green"},
+	})
+	if got := receive(all); len(got) != 2 {
+		t.Fatalf("first publish sent %d events, want 2", len(got))
+	}
+	if got := receive(allowed); len(got) != 1 || got[0].Key != "/kube/deployment/shop/api" {
+		t.Fatalf("first publish sent %v to the allowed subscriber", got)
+	}
+
+	stream.Publish([]model.BadgeStatus{
+		{Key: "/kube/deployment/shop/api", Label: "api", Message: "0/1 Unavailable", Color: "red", Allowed: true},
+		{Key: "/kube/deployment/shop/admin", Label: "admin", Message: "1/1 Available", Color: "green"},
+	})
+	got := receive(all)
+	if len(got) != 1 || got[0].Color != "red" || got[0].Message != "0/1 Unavailable" {
+		t.Fatalf("second publish sent %v, want only the changed badge", got)
+	}
+
+	late := stream.Subscribe(true)
+	if got := receive(late); len(got) != 1 || got[0].Color != "red" {
+		t.Fatalf("new subscriber received %v, want the current allowed state", got)
+	}
+
+	stream.Unsubscribe(all)
+	if _, ok := <-all.Events; ok {
+		t.Fatal("Unsubscribe() did not close the channel")
+	}
+}
+
+func TestStreamServiceRemoved(t *testing.T) {
+	stream := NewStreamService(fakeNotifier{}, fakeNotifier{})
+	all := stream.Subscribe(false)
+	allowed := stream.Subscribe(true)
+
+	api := model.BadgeStatus{Key: "/kube/deployment/shop/api", Label: "api", Message: "1/1 Available", Color: "green", Allowed: true}
+	admin := model.BadgeStatus{Key: "/kube/deployment/shop/admin", Label: "admin", Message: "1/1 Available", Color: "green"}
+	stream.Publish([]model.BadgeStatus{api, admin})
+	receive(all)
+	receive(allowed)
+
+	api.Allowed = false
+	stream.Publish([]model.BadgeStatus{api, admin})
+	if got := receive(allowed); len(got) != 1 || !got[0].Removed || got[0].Key != api.Key || got[0].Message != "" {
+		t.Fatalf("unpublishing sent %v to the allowed subscriber, want a removed event", got)
+	}
+	if got := receive(all); len(got) != 1 || got[0].Removed {
+		t.Fatalf("unpublishing sent %v to the subscriber of every badge, want the new state", got)
+	}
+
+	stream.Publish([]model.BadgeStatus{api})
+	if got := receive(allowed); len(got) != 0 {
+		t.Fatalf("a deleted badge that was not allowed sent %v to the allowed subscriber", got)
+	}
+	if got := receive(all); len(got) != 1 || !got[0].Removed || got[0].Key != admin.Key {
+		t.Fatalf("a deleted badge sent %v, want a removed event", got)
+	}
+}
+
+func TestStreamServiceSlowSubscriber(t *testing.T) {
+	stream := NewStreamService(fakeNotifier{}, fakeNotifier{})
+	slow := stream.Subscribe(false)
+
+	for i := 0; i <= streamBuffer; i++ {
+		stream.Publish([]model.BadgeStatus{{Key: "/probe/api", Message: "up", Color: []string{"green", "red"}[i%2]}})
+	}
+	if stream.active() {
+		t.Fatal("a subscriber that fell behind was not dropped")
+	}
+	count := 0
+	for range slow.Events {
+		count++
+	}
+	if count != streamBuffer {
+		t.Errorf("slow subscriber received %d events, want %d", count, streamBuffer)
+	}
+}
@@ -0,0 +1,49 @@
+package signing
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const (
+	// SignatureParam and ExpiresParam are the query parameters of a signed
+	// badge URL.
+	SignatureParam = "sig"
+	ExpiresParam   = "exp"
+)
+
+// Sign returns the signature granting access to the badge key until exp.
+func Sign(secret []byte, key string, exp time.Time) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(key + "\n" + strconv.FormatInt(exp.Unix(), 10)))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// Query returns the query string of a signed URL for the badge key.
+func Query(secret []byte, key string, exp time.Time) string {
+	return url.Values{
+		ExpiresParam:   {strconv.FormatInt(exp.Unix(), 10)},
+		SignatureParam: {Sign(secret, key, exp)},
+	}.Encode()
+}
+
+// Verify reports whether sig signs the badge key until exp, given in unix
+// seconds, and exp has not passed. It is always false without a secret.
+func Verify(secret []byte, key, sig, exp string, now time.Time) bool {
+	if len(secret) == 0 || len(sig) == 0 {
+		return false
+	}
+	seconds, err := strconv.ParseInt(exp, 10, 64)
+	if err != nil {
+		return false
+	}
+	expires := time.Unix(seconds, 0)
+	if !now.Before(expires) {
+		return false
+	}
+	return hmac.Equal([]byte(sig), []byte(Sign(secret, key, expires)))
+}
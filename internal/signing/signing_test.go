@@ -0,0 +1,54 @@
+package signing
+
+import (
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestVerify(t *testing.T) {
+	secret := []byte("secret")
+	now := time.Unix(1_700_000_000, 0)
+	exp := now.Add(time.Hour)
+	sig := Sign(secret, "/kube/deployment/shop/api", exp)
+	expString := strconv.FormatInt(exp.Unix(), 10)
+
+	tests := []struct {
+		name   string
+		secret []byte
+		key    string
+		sig    string
+		exp    string
+		now    time.Time
+		want   bool
+	}{
+		{"valid", secret, "/kube/deployment/shop/api", sig, expString, now, true},
+		{"expired", secret, "/kube/deployment/shop/api", sig, expString, exp, false},
+		{"other key", secret, "/kube/deployment/shop/admin", sig, expString, now, false},
+		{"extended expiry", secret, "/kube/deployment/shop/api", sig, strconv.FormatInt(exp.Add(time.Hour).Unix(), 10), now, false},
+		{"other secret", []byte("other"), "/kube/deployment/shop/api", sig, expString, now, false},
+		{"no secret", nil, "/kube/deployment/shop/api", Sign(nil, "/kube/deployment/shop/api", exp), expString, now, false},
+		{"no signature", secret, "/kube/deployment/shop/api", "", expString, now, false},
+		{"invalid expiry", secret, "/kube/deployment/shop/api", sig, "soon", now, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Verify(tt.secret, tt.key, tt.sig, tt.exp, tt.now); got != tt.want {
+				t.Errorf("Verify() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQuery(t *testing.T) {
+	secret := []byte("secret")
+	exp := time.Unix(1_700_000_000, 0)
+	values, err := url.ParseQuery(Query(secret, "/probe/api", exp))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !Verify(secret, "/probe/api", values.Get(SignatureParam), values.Get(ExpiresParam), exp.Add(-time.Second)) {
+		t.Errorf("Query() = %v does not verify", values)
+	}
+}
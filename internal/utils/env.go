@@ -3,6 +3,7 @@ package utils
 import (
 	"os"
 	"strconv"
+	"strings"
 )
 
 func GetEnv(key string, def string) string {
@@ -13,6 +14,17 @@ func GetEnv(key string, def string) string {
 	return value
 }
 
+// GetEnvAsSlice splits a comma-separated variable, dropping empty items.
+func GetEnvAsSlice(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); len(value) > 0 {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
 func GetEnvAsInt(key string, def int) int {
 	value, ok := os.LookupEnv(key)
 	if !ok {
@@ -24,3 +36,15 @@ func GetEnvAsInt(key string, def int) int {
 	}
 	return v
 }
+
+func GetEnvAsBool(key string, def bool) bool {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return def
+	}
+	v, err := strconv.ParseBool(value)
+	if err != nil {
+		return def
+	}
+	return v
+}
@@ -42,3 +42,17 @@ func TestGetEnvAsInt(t *testing.T) {
 		t.Errorf("Expected %d, but got %d", expectedValue, v)
 	}
 }
+
+func TestGetEnvAsSlice(t *testing.T) {
+	const envKey = "TEST_ENV"
+
+	os.Unsetenv(envKey)
+	if v := GetEnvAsSlice(envKey); len(v) != 0 {
+		t.Errorf("Expected no values, but got %q", v)
+	}
+
+	os.Setenv(envKey, " https://a.example , ,https://b.example")
+	if v := GetEnvAsSlice(envKey); len(v) != 2 || v[0] != "https://a.example" || v[1] != "https://b.example" {
+		t.Errorf("Unexpected values %q", v)
+	}
+}
@@ -17,11 +17,14 @@ const (
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 // +kubebuilder:object:root=true
 // +kubebuilder:resource:shortName=kubebadge
+// +kubebuilder:subresource:status
 // +kubebuilder:printcolumn:name="Type",type=string,JSONPath=`.spec.type`
 // +kubebuilder:printcolumn:name="OriginalURL",type=string,JSONPath=`.spec.originalURL`
 // +kubebuilder:printcolumn:name="DisplayName",type=string,JSONPath=`.spec.displayName`
 // +kubebuilder:printcolumn:name="OwnerNamespace",type=string,JSONPath=`.spec.ownerNamespace`
 // +kubebuilder:printcolumn:name="Allowed",type=boolean,JSONPath=`.spec.allowed`
+// +kubebuilder:printcolumn:name="Message",type=string,JSONPath=`.status.message`
+// +kubebuilder:printcolumn:name="Color",type=string,JSONPath=`.status.color`
 
 // KubeBadge is the Schema for the kubebadges API.
 type KubeBadge struct {
@@ -29,6 +32,9 @@ type KubeBadge struct {
 	metav1.ObjectMeta `json:"metadata,omitempty"`
 
 	Spec KubeBadgeSpec `json:"spec"`
+
+	// +optional
+	Status KubeBadgeStatus `json:"status,omitempty"`
 }
 
 // KubeBadgeSpec defines the desired state of KubeBadge.
@@ -66,12 +72,59 @@ type KubeBadgeSpec struct {
 	// +kubebuilder:validation:Description="Allowed specifies if the badge is allowed to public access."
 	Allowed bool `json:"allowed"`
 
+	// +optional
+	// +kubebuilder:validation:Type=boolean
+	// +kubebuilder:validation:Description="Notify opts the badge in to status-change notifications."
+	Notify bool `json:"notify,omitempty"`
+
+	// +optional
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Description="StatusGroup is the status page section of the badge. Defaults to OwnerNamespace."
+	StatusGroup string `json:"statusGroup,omitempty"`
+
 	// +optional
 	Custom Custom `json:"custom,omitempty"`
+
+	// +optional
+	Summary Summary `json:"summary,omitempty"`
+
+	// +optional
+	Group Group `json:"group,omitempty"`
+
+	// +optional
+	Appearance Appearance `json:"appearance,omitempty"`
+}
+
+// KubeBadgeStatus is the observed state of KubeBadge.
+type KubeBadgeStatus struct {
+	// +optional
+	// +kubebuilder:validation:Description="ObservedGeneration is the generation of the spec the state was computed for."
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// +optional
+	// +kubebuilder:validation:Description="Message is the message of the badge as last computed."
+	Message string `json:"message,omitempty"`
+
+	// +optional
+	// +kubebuilder:validation:Description="Color is the color of the badge as last computed."
+	Color string `json:"color,omitempty"`
+
+	// +optional
+	// +kubebuilder:validation:Description="LastTransitionTime is when the color of the badge last changed."
+	LastTransitionTime *metav1.Time `json:"lastTransitionTime,omitempty"`
+
+	// +optional
+	// +kubebuilder:validation:Description="LastError is why the badge could not be computed, if it could not."
+	LastError string `json:"lastError,omitempty"`
+
+	// +optional
+	// +kubebuilder:validation:Description="OrphanedSince is when the resource of the badge was first found missing."
+	OrphanedSince *metav1.Time `json:"orphanedSince,omitempty"`
 }
 
 type Custom struct {
 	// +optional
+	// +kubebuilder:validation:Description="Type is the probe to run against the address: http, tcp or grpc-health."
 	Type string `json:"type"`
 
 	// +optional
@@ -79,6 +132,78 @@ type Custom struct {
 
 	// +optional
 	Port int `json:"port"`
+
+	// +optional
+	// +kubebuilder:validation:Minimum=5
+	// +kubebuilder:validation:Description="IntervalSeconds is how often the probe runs. Defaults to 60."
+	IntervalSeconds int `json:"intervalSeconds,omitempty"`
+
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Description="TimeoutSeconds is how long a probe may take before it counts as down. Defaults to 5."
+	TimeoutSeconds int `json:"timeoutSeconds,omitempty"`
+}
+
+// Summary selects the workloads counted by a namespace summary badge.
+type Summary struct {
+	// +optional
+	// +kubebuilder:validation:Description="Include is a label selector; only matching workloads are counted."
+	Include string `json:"include,omitempty"`
+
+	// +optional
+	// +kubebuilder:validation:Description="Exclude is a label selector; matching workloads are not counted."
+	Exclude string `json:"exclude,omitempty"`
+}
+
+// Group selects the objects rolled up by a group badge.
+type Group struct {
+	// +optional
+	// +kubebuilder:validation:Description="Namespace holds the objects. Empty selects every namespace."
+	Namespace string `json:"namespace,omitempty"`
+
+	// +optional
+	// +kubebuilder:validation:Description="Selector is a label selector matching the objects of the group."
+	Selector string `json:"selector,omitempty"`
+
+	// +optional
+	// +kubebuilder:validation:Description="Kinds limits the group to deployment, statefulset, daemonset, job or pod. Empty includes them all."
+	Kinds []string `json:"kinds,omitempty"`
+
+	// +optional
+	// +kubebuilder:validation:Enum=worst;majority;all-green
+	// +kubebuilder:validation:Description="Aggregation combines the member colors: worst, majority or all-green. Defaults to worst."
+	Aggregation string `json:"aggregation,omitempty"`
+}
+
+// Appearance customizes how a badge is drawn. It does not change the
+// computed state, so status, metrics, uptime and notifications keep the
+// colors of the badge rules.
+type Appearance struct {
+	// +optional
+	// +kubebuilder:validation:Description="LabelColor is the color of the label, a shields color name or hex value."
+	LabelColor string `json:"labelColor,omitempty"`
+
+	// +optional
+	// +kubebuilder:validation:Description="Colors maps a message, or a computed color such as green or red, to the color drawn instead."
+	Colors map[string]string `json:"colors,omitempty"`
+
+	// +optional
+	// +kubebuilder:validation:Description="MessageTemplate is a Go template of the message, with .Label, .Message and .Color."
+	MessageTemplate string `json:"messageTemplate,omitempty"`
+
+	// +optional
+	// +kubebuilder:validation:Description="Logo is a simple-icons name, such as kubernetes."
+	Logo string `json:"logo,omitempty"`
+
+	// +optional
+	// +kubebuilder:validation:MaxLength=16384
+	// +kubebuilder:validation:Description="LogoSVG is an SVG document drawn as the logo. It wins over Logo."
+	LogoSVG string `json:"logoSVG,omitempty"`
+
+	// +optional
+	// +kubebuilder:validation:Enum=flat;flat-square;plastic;for-the-badge;social
+	// +kubebuilder:validation:Description="Style is the default style, the style query parameter wins."
+	Style string `json:"style,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -23,6 +23,29 @@ import (
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Appearance) DeepCopyInto(out *Appearance) {
+	*out = *in
+	if in.Colors != nil {
+		in, out := &in.Colors, &out.Colors
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Appearance.
+func (in *Appearance) DeepCopy() *Appearance {
+	if in == nil {
+		return nil
+	}
+	out := new(Appearance)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Custom) DeepCopyInto(out *Custom) {
 	*out = *in
@@ -39,12 +62,34 @@ func (in *Custom) DeepCopy() *Custom {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Group) DeepCopyInto(out *Group) {
+	*out = *in
+	if in.Kinds != nil {
+		in, out := &in.Kinds, &out.Kinds
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Group.
+func (in *Group) DeepCopy() *Group {
+	if in == nil {
+		return nil
+	}
+	out := new(Group)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *KubeBadge) DeepCopyInto(out *KubeBadge) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 	return
 }
 
@@ -103,6 +148,9 @@ func (in *KubeBadgeList) DeepCopyObject() runtime.Object {
 func (in *KubeBadgeSpec) DeepCopyInto(out *KubeBadgeSpec) {
 	*out = *in
 	out.Custom = in.Custom
+	out.Summary = in.Summary
+	in.Group.DeepCopyInto(&out.Group)
+	in.Appearance.DeepCopyInto(&out.Appearance)
 	return
 }
 
@@ -115,3 +163,43 @@ func (in *KubeBadgeSpec) DeepCopy() *KubeBadgeSpec {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KubeBadgeStatus) DeepCopyInto(out *KubeBadgeStatus) {
+	*out = *in
+	if in.LastTransitionTime != nil {
+		in, out := &in.LastTransitionTime, &out.LastTransitionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.OrphanedSince != nil {
+		in, out := &in.OrphanedSince, &out.OrphanedSince
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubeBadgeStatus.
+func (in *KubeBadgeStatus) DeepCopy() *KubeBadgeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(KubeBadgeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Summary) DeepCopyInto(out *Summary) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Summary.
+func (in *Summary) DeepCopy() *Summary {
+	if in == nil {
+		return nil
+	}
+	out := new(Summary)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -99,6 +99,18 @@ func (c *FakeKubeBadges) Update(ctx context.Context, kubeBadge *v1.KubeBadge, op
 	return obj.(*v1.KubeBadge), err
 }
 
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *FakeKubeBadges) UpdateStatus(ctx context.Context, kubeBadge *v1.KubeBadge, opts metav1.UpdateOptions) (*v1.KubeBadge, error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateSubresourceAction(kubebadgesResource, "status", c.ns, kubeBadge), &v1.KubeBadge{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1.KubeBadge), err
+}
+
 // Delete takes name of the kubeBadge and deletes it. Returns an error if one occurs.
 func (c *FakeKubeBadges) Delete(ctx context.Context, name string, opts metav1.DeleteOptions) error {
 	_, err := c.Fake.
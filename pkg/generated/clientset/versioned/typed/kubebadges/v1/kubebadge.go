@@ -38,6 +38,7 @@ type KubeBadgesGetter interface {
 type KubeBadgeInterface interface {
 	Create(ctx context.Context, kubeBadge *v1.KubeBadge, opts metav1.CreateOptions) (*v1.KubeBadge, error)
 	Update(ctx context.Context, kubeBadge *v1.KubeBadge, opts metav1.UpdateOptions) (*v1.KubeBadge, error)
+	UpdateStatus(ctx context.Context, kubeBadge *v1.KubeBadge, opts metav1.UpdateOptions) (*v1.KubeBadge, error)
 	Delete(ctx context.Context, name string, opts metav1.DeleteOptions) error
 	DeleteCollection(ctx context.Context, opts metav1.DeleteOptions, listOpts metav1.ListOptions) error
 	Get(ctx context.Context, name string, opts metav1.GetOptions) (*v1.KubeBadge, error)
@@ -133,6 +134,22 @@ func (c *kubeBadges) Update(ctx context.Context, kubeBadge *v1.KubeBadge, opts m
 	return
 }
 
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *kubeBadges) UpdateStatus(ctx context.Context, kubeBadge *v1.KubeBadge, opts metav1.UpdateOptions) (result *v1.KubeBadge, err error) {
+	result = &v1.KubeBadge{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("kubebadges").
+		Name(kubeBadge.Name).
+		SubResource("status").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(kubeBadge).
+		Do(ctx).
+		Into(result)
+	return
+}
+
 // Delete takes name of the kubeBadge and deletes it. Returns an error if one occurs.
 func (c *kubeBadges) Delete(ctx context.Context, name string, opts metav1.DeleteOptions) error {
 	return c.client.Delete().